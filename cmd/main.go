@@ -8,6 +8,7 @@ import (
 	"go-fiber-boilerplate/database"
 	"go-fiber-boilerplate/internal/middlewares"
 	"go-fiber-boilerplate/internal/routes"
+	"go-fiber-boilerplate/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -18,9 +19,32 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Load access token signing keys
+	if err := services.LoadJWTKeys(cfg); err != nil {
+		log.Fatal("Failed to load JWT keys:", err)
+	}
+
+	// Select the password hashing algorithm
+	if err := services.ConfigurePasswordHashing(cfg); err != nil {
+		log.Fatal("Invalid password hashing configuration:", err)
+	}
+
+	// Register the validate tag rules that need config or the database
+	services.RegisterValidationRules(cfg)
+
 	// Connect to database
 	database.ConnectDB(cfg)
 
+	// Load revoked tokens and keep the cache in sync
+	revocationStore := services.GetRevocationStore()
+	if err := revocationStore.Load(); err != nil {
+		log.Fatal("Failed to load revoked tokens:", err)
+	}
+	revocationStore.StartCleanup(cfg.RevocationSyncInterval)
+
+	// Carry out scheduled blog publishing
+	services.StartBlogScheduler(cfg.BlogSchedulerInterval)
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		ErrorHandler: middlewares.ErrorHandler,
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,10 +17,28 @@ type Config struct {
 	DBPassword       string
 	DBName           string
 	Port             string
+	AppEnv           string
 	JWTSecret        string
 	AllowedOrigins   string
 	AllowCredentials bool
 
+	// Access token signing. Keys are *.pem files in JWTKeysDir named after
+	// their key ID; JWTSigningKeyID picks the one new tokens are signed with.
+	JWTKeysDir      string
+	JWTSigningKeyID string
+	JWTIssuer       string
+	JWTAudience     string
+
+	// Token lifetimes
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// How often revoked tokens are purged and re-synced from the database
+	RevocationSyncInterval time.Duration
+
+	// How often scheduled blog publishing and unpublishing is carried out
+	BlogSchedulerInterval time.Duration
+
 	// Email configuration
 	SMTPHost     string
 	SMTPPort     string
@@ -28,12 +49,62 @@ type Config struct {
 	// Frontend URL for reset password links
 	FrontendURL string
 
+	// Brute-force protection
+	MaxLoginAttempts      int
+	MaxLoginAttemptsPerIP int
+	LoginAttemptWindow    time.Duration
+	LoginLockoutBase      time.Duration
+	LoginLockoutMax       time.Duration
+	MaxResetEmailsPerHour int
+	ForgotPasswordIPLimit int
+
+	// Password hashing: "argon2id" or "bcrypt". Stored hashes using another
+	// algorithm or other parameters are upgraded on the next login.
+	PasswordHashAlgorithm string
+	Argon2Memory          int
+	Argon2Iterations      int
+	Argon2Parallelism     int
+	BcryptCost            int
+
+	// Password policy
+	PasswordMinLength      int
+	PasswordMinCharClasses int
+	PasswordRequireUpper   bool
+	PasswordRequireLower   bool
+	PasswordRequireDigit   bool
+	PasswordRequireSymbol  bool
+	PasswordCheckBreached  bool
+	BreachedPasswordsPath  string
+
+	// Lifetime of passwordless sign-in links
+	MagicLinkTTL time.Duration
+
+	// Issuer shown by authenticator apps for TOTP two-factor authentication
+	TOTPIssuer string
+
+	// Email verification: "off", "login" or "blog" decides what an
+	// unverified account is blocked from
+	EmailVerificationEnforcement string
+	VerificationResendInterval   time.Duration
+
+	// OpenID Connect providers keyed by name, e.g. "google"
+	OIDCProviders map[string]OIDCProviderConfig
+
 	// Cloudinary configuration
 	CloudinaryCloudName string
 	CloudinaryAPIKey    string
 	CloudinaryAPISecret string
 }
 
+type OIDCProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
 func LoadConfig() *Config {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
@@ -42,17 +113,32 @@ func LoadConfig() *Config {
 
 	allowCredentials := getEnv("CORS_ALLOW_CREDENTIALS", "false") == "true"
 
-	return &Config{
+	cfg := &Config{
 		DBHost:           getEnv("DB_HOST", "localhost"),
 		DBPort:           getEnv("DB_PORT", "5432"),
 		DBUser:           getEnv("DB_USER", "postgres"),
 		DBPassword:       getEnv("DB_PASSWORD", "admin"),
 		DBName:           getEnv("DB_NAME", "go_fiber_db"),
 		Port:             getEnv("PORT", "8000"),
+		AppEnv:           getEnv("APP_ENV", "production"),
 		JWTSecret:        getEnv("JWT_SECRET", "default_secret"),
 		AllowedOrigins:   getEnv("CORS_ALLOWED_ORIGINS", "*"),
 		AllowCredentials: allowCredentials,
 
+		// Access token signing
+		JWTKeysDir:      getEnv("JWT_KEYS_DIR", ""),
+		JWTSigningKeyID: getEnv("JWT_SIGNING_KEY_ID", ""),
+		JWTIssuer:       getEnv("JWT_ISSUER", "blog-app-api"),
+		JWTAudience:     getEnv("JWT_AUDIENCE", "blog-app"),
+
+		// Token lifetimes
+		AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		RevocationSyncInterval: getEnvDuration("REVOCATION_SYNC_INTERVAL", time.Minute),
+
+		BlogSchedulerInterval: getEnvDuration("BLOG_SCHEDULER_INTERVAL", 30*time.Second),
+
 		// Email configuration
 		SMTPHost:     getEnv("SMTP_HOST", "smtp.gmail.com"),
 		SMTPPort:     getEnv("SMTP_PORT", "587"),
@@ -63,11 +149,95 @@ func LoadConfig() *Config {
 		// Frontend URL
 		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
 
+		// Brute-force protection
+		MaxLoginAttempts:      getEnvInt("MAX_LOGIN_ATTEMPTS", 5),
+		MaxLoginAttemptsPerIP: getEnvInt("MAX_LOGIN_ATTEMPTS_PER_IP", 20),
+		LoginAttemptWindow:    getEnvDuration("LOGIN_ATTEMPT_WINDOW", time.Hour),
+		LoginLockoutBase:      getEnvDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+		LoginLockoutMax:       getEnvDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+		MaxResetEmailsPerHour: getEnvInt("MAX_RESET_EMAILS_PER_HOUR", 3),
+		ForgotPasswordIPLimit: getEnvInt("FORGOT_PASSWORD_IP_LIMIT", 10),
+
+		// Password hashing
+		PasswordHashAlgorithm: getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+		Argon2Memory:          getEnvInt("ARGON2_MEMORY_KB", 64*1024),
+		Argon2Iterations:      getEnvInt("ARGON2_ITERATIONS", 3),
+		Argon2Parallelism:     getEnvInt("ARGON2_PARALLELISM", 2),
+		BcryptCost:            getEnvInt("BCRYPT_COST", 10),
+
+		// Password policy
+		PasswordMinLength:      getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMinCharClasses: getEnvInt("PASSWORD_MIN_CHAR_CLASSES", 2),
+		PasswordRequireUpper:   getEnv("PASSWORD_REQUIRE_UPPER", "false") == "true",
+		PasswordRequireLower:   getEnv("PASSWORD_REQUIRE_LOWER", "false") == "true",
+		PasswordRequireDigit:   getEnv("PASSWORD_REQUIRE_DIGIT", "false") == "true",
+		PasswordRequireSymbol:  getEnv("PASSWORD_REQUIRE_SYMBOL", "false") == "true",
+		PasswordCheckBreached:  getEnv("PASSWORD_CHECK_BREACHED", "true") == "true",
+		BreachedPasswordsPath:  getEnv("BREACHED_PASSWORDS_PATH", ""),
+
+		MagicLinkTTL: getEnvDuration("MAGIC_LINK_TTL", 15*time.Minute),
+
+		TOTPIssuer: getEnv("TOTP_ISSUER", "Blog App"),
+
+		// Email verification
+		EmailVerificationEnforcement: getEnv("EMAIL_VERIFICATION_ENFORCEMENT", "off"),
+		VerificationResendInterval:   getEnvDuration("VERIFICATION_RESEND_INTERVAL", 2*time.Minute),
+
+		OIDCProviders: loadOIDCProviders(),
+
 		// Cloudinary configuration
 		CloudinaryCloudName: getEnv("CLOUDINARY_CLOUD_NAME", ""),
 		CloudinaryAPIKey:    getEnv("CLOUDINARY_API_KEY", ""),
 		CloudinaryAPISecret: getEnv("CLOUDINARY_API_SECRET", ""),
 	}
+
+	// The default secret is public, anyone could forge verification, MFA
+	// and unlock tokens with it
+	if !cfg.IsDevelopment() && cfg.JWTSecret == "default_secret" {
+		log.Fatal("JWT_SECRET must be set outside development")
+	}
+
+	return cfg
+}
+
+// IsDevelopment reports whether the app runs with APP_ENV=development. An
+// unset APP_ENV counts as production, so the development shortcuts (default
+// secret, throwaway signing key) have to be asked for explicitly.
+func (c *Config) IsDevelopment() bool {
+	return c.AppEnv == "development"
+}
+
+// loadOIDCProviders reads the providers listed in OIDC_PROVIDERS, each
+// configured through OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET,
+// _REDIRECT_URL and optionally _SCOPES
+func loadOIDCProviders() map[string]OIDCProviderConfig {
+	providers := make(map[string]OIDCProviderConfig)
+
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    strings.TrimSuffix(getEnv(prefix+"ISSUER", ""), "/"),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		}
+
+		if provider.IssuerURL == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			log.Printf("OIDC provider %s is missing issuer, client ID or redirect URL, skipping", name)
+			continue
+		}
+
+		providers[name] = provider
+	}
+
+	return providers
 }
 
 func getEnv(key, defaultValue string) string {
@@ -76,3 +246,31 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s, using default %s", key, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer for %s, using default %d", key, defaultValue)
+		return defaultValue
+	}
+	return number
+}
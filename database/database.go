@@ -20,7 +20,8 @@ func ConnectDB(cfg *config.Config) {
 
 	var err error
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent), // Mengubah level log menjadi Silent
+		Logger:         logger.Default.LogMode(logger.Silent), // Mengubah level log menjadi Silent
+		TranslateError: true,
 	})
 
 	if err != nil {
@@ -29,18 +30,84 @@ func ConnectDB(cfg *config.Config) {
 
 	log.Println("Database connected successfully")
 
-	// Auto migrate the schema
-	err = DB.AutoMigrate(
+	if err := Migrate(DB); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	log.Println("Database migration completed")
+}
+
+// Migrate brings the schema of db up to date
+func Migrate(db *gorm.DB) error {
+	if err := dropBlogSlugConstraint(db); err != nil {
+		return fmt.Errorf("migrate blog slugs: %w", err)
+	}
+
+	if err := db.AutoMigrate(
 		&models.User{},
 		&models.Sample{},
+		&models.Category{},
+		&models.Tag{},
 		&models.Blog{},
-	)
+		&models.BlogSlug{},
+		&models.BlogRevision{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.UserTokenRevocation{},
+		&models.RecoveryCode{},
+		&models.OneTimeToken{},
+		&models.AuditLog{},
+		&models.APIKey{},
+		&models.UserIdentity{},
+		&models.OIDCAuthRequest{},
+		&models.Session{},
+	); err != nil {
+		return err
+	}
 
-	if err != nil {
-		log.Fatal("Failed to migrate database:", err)
+	if err := migrateBlogSearch(db); err != nil {
+		return fmt.Errorf("migrate blog search: %w", err)
 	}
 
-	log.Println("Database migration completed")
+	if err := backfillPublishedAt(db); err != nil {
+		return fmt.Errorf("backfill blog publish dates: %w", err)
+	}
+
+	return nil
+}
+
+// dropBlogSlugConstraint removes the old table-wide unique constraint on
+// blogs.slug, which kept soft-deleted blogs holding their slug forever. The
+// partial index idx_blogs_slug replaces it. Older GORM versions named the
+// constraint after Postgres' default, newer ones use the uni_ prefix.
+func dropBlogSlugConstraint(db *gorm.DB) error {
+	for _, name := range []string{"uni_blogs_slug", "blogs_slug_key"} {
+		if err := db.Exec(`ALTER TABLE IF EXISTS blogs DROP CONSTRAINT IF EXISTS ` + name).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateBlogSearch adds the full-text search column, which GORM cannot
+// declare because it is generated. Title words weigh more than content.
+// The 'simple' configuration does no stemming, so it suits any language.
+func migrateBlogSearch(db *gorm.DB) error {
+	if err := db.Exec(`ALTER TABLE blogs ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(content, '')), 'B')
+		) STORED`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`CREATE INDEX IF NOT EXISTS idx_blogs_search_vector ON blogs USING GIN (search_vector)`).Error
+}
+
+// backfillPublishedAt dates blogs published before published_at existed by
+// their creation, the closest record there is
+func backfillPublishedAt(db *gorm.DB) error {
+	return db.Exec(`UPDATE blogs SET published_at = created_at WHERE published AND published_at IS NULL`).Error
 }
 
 func GetDB() *gorm.DB {
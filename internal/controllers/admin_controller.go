@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"strconv"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminController struct {
+	adminService *services.AdminService
+}
+
+func NewAdminController(cfg *config.Config) *AdminController {
+	return &AdminController{
+		adminService: services.NewAdminService(cfg),
+	}
+}
+
+func (h *AdminController) GetUsers(c *fiber.Ctx) error {
+	var params models.AdminUserQueryParams
+	if err := parseQuery(c, &params); err != nil {
+		return err
+	}
+	params.Page, params.Limit = paginationDefaults(params.Page, params.Limit)
+
+	users, total, err := h.adminService.GetUsers(params)
+	if err != nil {
+		return err
+	}
+
+	responses := []models.UserResponse{}
+	for _, user := range users {
+		responses = append(responses, user.ToResponse())
+	}
+
+	return c.JSON(response.Paginated(responses, params.Page, params.Limit, total))
+}
+
+func (h *AdminController) GetUser(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	user, err := h.adminService.GetUser(uint(id))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("", user.ToResponse()))
+}
+
+func (h *AdminController) UpdateUserStatus(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req models.UpdateUserStatusRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	user, err := h.adminService.SetUserActive(currentActor(c), clientMeta(c), uint(id), *req.IsActive)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("User status updated successfully", user.ToResponse()))
+}
+
+func (h *AdminController) UpdateUserRole(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req models.UpdateUserRoleRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	user, err := h.adminService.ChangeUserRole(currentActor(c), clientMeta(c), uint(id), req.Role)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("User role updated successfully", user.ToResponse()))
+}
+
+func (h *AdminController) SendPasswordReset(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.adminService.SendPasswordReset(currentActor(c), clientMeta(c), uint(id)); err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Password reset email sent", nil))
+}
+
+func (h *AdminController) GetUserBlogs(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	page, limit = paginationDefaults(page, limit)
+
+	blogs, total, err := h.adminService.GetUserBlogs(uint(id), page, limit)
+	if err != nil {
+		return err
+	}
+
+	responses := []models.BlogResponse{}
+	for _, blog := range blogs {
+		responses = append(responses, blog.ToResponse())
+	}
+
+	return c.JSON(response.Paginated(responses, page, limit, total))
+}
+
+func (h *AdminController) GetAuditLogs(c *fiber.Ctx) error {
+	var params models.AuditLogQueryParams
+	if err := parseQuery(c, &params); err != nil {
+		return err
+	}
+	params.Page, params.Limit = paginationDefaults(params.Page, params.Limit)
+
+	logs, total, err := h.adminService.GetAuditLogs(params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Paginated(logs, params.Page, params.Limit, total))
+}
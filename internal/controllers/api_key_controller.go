@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"strconv"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyController struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyController(cfg *config.Config) *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: services.NewAPIKeyService(cfg),
+	}
+}
+
+func (h *APIKeyController) GetAPIKeys(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	apiKeys, err := h.apiKeyService.GetAPIKeys(userID)
+	if err != nil {
+		return err
+	}
+
+	responses := make([]models.APIKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		responses = append(responses, apiKey.ToResponse())
+	}
+
+	return c.JSON(response.Success("", responses))
+}
+
+func (h *APIKeyController) CreateAPIKey(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.CreateAPIKeyRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.apiKeyService.CreateAPIKey(userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success("API key created successfully. Store it now, it will not be shown again", result))
+}
+
+func (h *APIKeyController) RevokeAPIKey(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid API key ID")
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(userID, uint(id)); err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("API key revoked successfully", nil))
+}
@@ -1,9 +1,12 @@
 package controllers
 
 import (
+    "time"
+
     "go-fiber-boilerplate/config"
     "go-fiber-boilerplate/internal/services"
     "go-fiber-boilerplate/internal/models"
+    "go-fiber-boilerplate/pkg/response"
     "github.com/gofiber/fiber/v2"
 )
 
@@ -19,112 +22,191 @@ func NewAuthController(cfg *config.Config) *AuthController {
 
 func (ctrl *AuthController) Register(c *fiber.Ctx) error {
     var req models.CreateUserRequest
-    if err := c.BodyParser(&req); err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid request body",
-        })
+    if err := parseBody(c, &req); err != nil {
+        return err
     }
 
-    response, err := ctrl.authService.Register(req)
+    result, err := ctrl.authService.Register(req)
     if err != nil {
-        if err.Error() == "user with this email already exists" {
-            return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-                "error": err.Error(),
-            })
-        }
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to register user",
-        })
+        return err
     }
 
-    return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-        "message": "User registered successfully",
-        "data":    response,
-    })
+    return c.Status(fiber.StatusCreated).JSON(response.Success("User registered successfully", result))
 }
 
 func (ctrl *AuthController) Login(c *fiber.Ctx) error {
     var req models.LoginRequest
-    if err := c.BodyParser(&req); err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid request body",
-        })
+    if err := parseBody(c, &req); err != nil {
+        return err
+    }
+
+    result, err := ctrl.authService.Login(req, clientMeta(c))
+    if err != nil {
+        return err
+    }
+
+    return c.JSON(loginResponse(result))
+}
+
+func (ctrl *AuthController) VerifyMFA(c *fiber.Ctx) error {
+    var req models.MFAVerifyRequest
+    if err := parseBody(c, &req); err != nil {
+        return err
     }
 
-    response, err := ctrl.authService.Login(req)
+    result, err := ctrl.authService.VerifyMFA(req, clientMeta(c))
     if err != nil {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": err.Error(),
-        })
+        return err
     }
 
-    return c.JSON(fiber.Map{
-        "message": "Login successful",
-        "data":    response,
-    })
+    return c.JSON(response.Success("Login successful", result))
+}
+
+func (ctrl *AuthController) RefreshToken(c *fiber.Ctx) error {
+    var req models.RefreshTokenRequest
+    if err := parseBody(c, &req); err != nil {
+        return err
+    }
+
+    result, err := ctrl.authService.RefreshToken(req.RefreshToken, clientMeta(c))
+    if err != nil {
+        return err
+    }
+
+    return c.JSON(response.Success("Token refreshed successfully", result))
+}
+
+func (ctrl *AuthController) Logout(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    tokenID, _ := c.Locals("tokenID").(string)
+    sessionID, _ := c.Locals("sessionID").(string)
+    tokenExpiresAt, _ := c.Locals("tokenExpiresAt").(time.Time)
+
+    var req models.LogoutRequest
+    if len(c.Body()) > 0 {
+        if err := parseBody(c, &req); err != nil {
+            return err
+        }
+    }
+
+    if err := ctrl.authService.Logout(userID, tokenID, sessionID, tokenExpiresAt, req.RefreshToken); err != nil {
+        return err
+    }
+
+    return c.JSON(response.Success("Logged out successfully", nil))
+}
+
+func (ctrl *AuthController) LogoutAll(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+
+    if err := ctrl.authService.LogoutAll(userID); err != nil {
+        return err
+    }
+
+    return c.JSON(response.Success("Logged out from all devices successfully", nil))
 }
 
 func (ctrl *AuthController) ForgotPassword(c *fiber.Ctx) error {
     var req models.ForgotPasswordRequest
-    if err := c.BodyParser(&req); err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid request body",
-        })
+    if err := parseBody(c, &req); err != nil {
+        return err
+    }
+
+    if err := ctrl.authService.ForgotPassword(req.Email, clientMeta(c)); err != nil {
+        return err
+    }
+
+    return c.JSON(response.Success("If the email exists, a reset link has been sent", nil))
+}
+
+func (ctrl *AuthController) RequestMagicLink(c *fiber.Ctx) error {
+    var req models.MagicLinkRequest
+    if err := parseBody(c, &req); err != nil {
+        return err
     }
 
-    err := ctrl.authService.ForgotPassword(req.Email)
+    if err := ctrl.authService.RequestMagicLink(req.Email, clientMeta(c)); err != nil {
+        return err
+    }
+
+    return c.JSON(response.Success("If the email exists, a sign-in link has been sent", nil))
+}
+
+func (ctrl *AuthController) VerifyMagicLink(c *fiber.Ctx) error {
+    var req models.MagicLinkVerifyRequest
+    if err := parseBody(c, &req); err != nil {
+        return err
+    }
+
+    result, err := ctrl.authService.VerifyMagicLink(req.Token, clientMeta(c))
     if err != nil {
-        if err.Error() == "email is required" || err.Error() == "invalid email format" {
-            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-                "error": err.Error(),
-            })
-        }
-        if err.Error() == "failed to send reset email" {
-            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-                "error": err.Error(),
-            })
+        return err
+    }
+
+    return c.JSON(loginResponse(result))
+}
+
+func (ctrl *AuthController) VerifyEmail(c *fiber.Ctx) error {
+    var req models.VerifyEmailRequest
+    if c.Method() == fiber.MethodGet {
+        req.Token = c.Query("token")
+        if err := validateRequest(c, &req); err != nil {
+            return err
         }
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Database error",
-        })
+    } else if err := parseBody(c, &req); err != nil {
+        return err
     }
 
-    return c.JSON(fiber.Map{
-        "message": "If the email exists, a reset link has been sent",
-    })
+    if err := ctrl.authService.VerifyEmail(req.Token); err != nil {
+        return err
+    }
+
+    return c.JSON(response.Success("Email has been verified successfully", nil))
+}
+
+func (ctrl *AuthController) ResendVerification(c *fiber.Ctx) error {
+    var req models.ResendVerificationRequest
+    if err := parseBody(c, &req); err != nil {
+        return err
+    }
+
+    if err := ctrl.authService.ResendVerification(req.Email); err != nil {
+        return err
+    }
+
+    return c.JSON(response.Success("If the email exists and is not verified, a verification link has been sent", nil))
+}
+
+func (ctrl *AuthController) UnlockAccount(c *fiber.Ctx) error {
+    var req models.UnlockAccountRequest
+    if err := parseBody(c, &req); err != nil {
+        return err
+    }
+
+    if err := ctrl.authService.UnlockAccount(req.Token); err != nil {
+        return err
+    }
+
+    return c.JSON(response.Success("Account has been unlocked successfully", nil))
 }
 
 func (ctrl *AuthController) ResetPassword(c *fiber.Ctx) error {
     var req models.ResetPasswordRequest
-    if err := c.BodyParser(&req); err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid request body",
-        })
+    if err := parseBody(c, &req); err != nil {
+        return err
     }
 
-    err := ctrl.authService.ResetPassword(req.Token, req.NewPassword)
-    if err != nil {
-        switch err.Error() {
-        case "token and new password are required", "password must be at least 6 characters long":
-            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-                "error": err.Error(),
-            })
-        case "invalid or expired reset token":
-            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-                "error": err.Error(),
-            })
-        case "user not found":
-            return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-                "error": err.Error(),
-            })
-        default:
-            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-                "error": "Failed to reset password",
-            })
-        }
+    if err := ctrl.authService.ResetPassword(req.Token, req.NewPassword); err != nil {
+        return err
     }
 
-    return c.JSON(fiber.Map{
-        "message": "Password has been reset successfully",
-    })
-}
\ No newline at end of file
+    return c.JSON(response.Success("Password has been reset successfully", nil))
+}
+
+// loginResponse tells a finished login apart from one waiting for the second factor
+func loginResponse(result *models.LoginResponse) response.APIResponse {
+    if result.MFARequired {
+        return response.Success("Two-factor authentication required", result)
+    }
+    return response.Success("Login successful", result)
+}
@@ -1,16 +1,21 @@
 package controllers
 
 import (
+	"net/url"
 	"strconv"
 
 	"go-fiber-boilerplate/config"
 	"go-fiber-boilerplate/internal/models"
 	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
+// BlogSlugRoute names the route serving blogs by slug, which old slugs are
+// redirected to
+const BlogSlugRoute = "blogs.slug"
+
 type BlogController struct {
 	blogService *services.BlogService
 }
@@ -32,16 +37,18 @@ func (h *BlogController) CreateBlog(c *fiber.Ctx) error {
 	content := c.FormValue("content")
 	published := c.FormValue("published") == "true"
 
-	if title == "" || content == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Title and content are required",
-		})
-	}
-
 	req := models.CreateBlogRequest{
-		Title:     title,
-		Content:   content,
-		Published: published,
+		Title:       title,
+		Content:     content,
+		Slug:        c.FormValue("slug"),
+		Published:   published,
+		PublishAt:   c.FormValue("publish_at"),
+		UnpublishAt: c.FormValue("unpublish_at"),
+		Category:    c.FormValue("category"),
+	}
+	req.Tags, _ = formList(c, "tags")
+	if err := validateRequest(c, &req); err != nil {
+		return err
 	}
 
 	file, err := c.FormFile("image")
@@ -51,61 +58,105 @@ func (h *BlogController) CreateBlog(c *fiber.Ctx) error {
 
 	blog, err := h.blogService.CreateBlog(userID, req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Blog created successfully",
-		"data":    blog.ToResponse(),
-	})
+	return c.Status(fiber.StatusCreated).JSON(response.Success("Blog created successfully", blog.ToResponse()))
 }
 func (h *BlogController) GetBlogs(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	var params models.BlogQueryParams
+	if err := parseQuery(c, &params); err != nil {
+		return err
+	}
+	params.Page, params.Limit = paginationDefaults(params.Page, params.Limit)
 
-	blogs, total, err := h.blogService.GetBlogs(page, limit)
+	blogs, total, err := h.blogService.GetBlogs(currentActor(c), params)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch blogs"})
+		return err
 	}
 
-	var responses []models.BlogResponse
+	responses := []models.BlogResponse{}
 	for _, blog := range blogs {
 		responses = append(responses, blog.ToResponse())
 	}
 
-	return c.JSON(fiber.Map{
-		"data": responses,
-		"pagination": fiber.Map{
-			"page":  page,
-			"limit": limit,
-			"total": total,
-		},
-	})
+	return c.JSON(response.Paginated(responses, params.Page, params.Limit, total))
+}
+
+// GetMyBlogs lists the caller's own blogs, drafts included
+func (h *BlogController) GetMyBlogs(c *fiber.Ctx) error {
+	var params models.BlogQueryParams
+	if err := parseQuery(c, &params); err != nil {
+		return err
+	}
+	params.Page, params.Limit = paginationDefaults(params.Page, params.Limit)
+
+	actor := currentActor(c)
+	params.UserID = actor.UserID
+
+	blogs, total, err := h.blogService.GetBlogs(actor, params)
+	if err != nil {
+		return err
+	}
+
+	responses := []models.BlogResponse{}
+	for _, blog := range blogs {
+		responses = append(responses, blog.ToResponse())
+	}
+
+	return c.JSON(response.Paginated(responses, params.Page, params.Limit, total))
 }
 func (h *BlogController) GetBlogById(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid blog ID"})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid blog ID")
 	}
 
-	blog, err := h.blogService.GetBlogById(uint(id))
+	blog, err := h.blogService.GetBlogById(currentActor(c), uint(id))
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Blog not found"})
+		return err
+	}
+
+	return c.JSON(response.Success("", blog.ToResponse()))
+}
+
+// GetBlogBySlug answers old slugs with a permanent redirect to the current one
+func (h *BlogController) GetBlogBySlug(c *fiber.Ctx) error {
+	blog, moved, err := h.blogService.GetBlogBySlug(currentActor(c), c.Params("slug"))
+	if err != nil {
+		return err
+	}
+
+	if moved {
+		location, err := slugLocation(c, blog.Slug)
+		if err != nil {
+			return err
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Database error"})
+		return c.Redirect(location, fiber.StatusMovedPermanently)
+	}
+
+	return c.JSON(response.Success("", blog.ToResponse()))
+}
+
+// slugLocation is the URL of a blog's slug route, keeping the query string
+// of the current request
+func slugLocation(c *fiber.Ctx, slug string) (string, error) {
+	location, err := c.GetRouteURL(BlogSlugRoute, fiber.Map{"slug": url.PathEscape(slug)})
+	if err != nil {
+		return "", err
 	}
 
-	return c.JSON(fiber.Map{"data": blog.ToResponse()})
+	if query := c.Context().QueryArgs().QueryString(); len(query) > 0 {
+		location += "?" + string(query)
+	}
+	return location, nil
 }
 func (h *BlogController) UpdateBlog(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
+	actor := currentActor(c)
 
 	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid blog ID"})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid blog ID")
 	}
 
 	title := c.FormValue("title")
@@ -113,48 +164,124 @@ func (h *BlogController) UpdateBlog(c *fiber.Ctx) error {
 	publishedStr := c.FormValue("published")
 
 	req := models.UpdateBlogRequest{
-		Title:   title,
-		Content: content,
+		Title:       title,
+		Content:     content,
+		Slug:        c.FormValue("slug"),
+		PublishAt:   c.FormValue("publish_at"),
+		UnpublishAt: c.FormValue("unpublish_at"),
 	}
 
 	if publishedStr != "" {
 		published := publishedStr == "true"
 		req.Published = &published
 	}
+	if tags, sent := formList(c, "tags"); sent {
+		req.Tags = &tags
+	}
+	if category, sent := formField(c, "category"); sent {
+		req.Category = &category
+	}
+	if err := validateRequest(c, &req); err != nil {
+		return err
+	}
 
 	file, err := c.FormFile("image")
 	if err == nil {
 		req.Image = file
 	}
 
-	blog, err := h.blogService.UpdateBlog(uint(id), userID, req)
+	blog, err := h.blogService.UpdateBlog(uint(id), actor, req)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Blog not found"})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
-	return c.JSON(fiber.Map{
-		"message": "Blog updated successfully",
-		"data":    blog.ToResponse(),
-	})
+	return c.JSON(response.Success("Blog updated successfully", blog.ToResponse()))
 }
 func (h *BlogController) DeleteBlog(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
+	actor := currentActor(c)
 
 	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid blog ID"})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid blog ID")
 	}
 
-	err = h.blogService.DeleteBlog(uint(id), userID)
+	if err := h.blogService.DeleteBlog(uint(id), actor); err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Blog deleted successfully", nil))
+}
+func (h *BlogController) GetRevisions(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Blog not found"})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid blog ID")
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	page, limit = paginationDefaults(page, limit)
+
+	revisions, total, err := h.blogService.GetRevisions(currentActor(c), uint(id), page, limit)
+	if err != nil {
+		return err
+	}
+
+	responses := []models.BlogRevisionResponse{}
+	for _, revision := range revisions {
+		responses = append(responses, revision.ToSummary())
+	}
+
+	return c.JSON(response.Paginated(responses, page, limit, total))
+}
+func (h *BlogController) GetRevision(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid blog ID")
+	}
+	number, err := strconv.Atoi(c.Params("rev"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid revision number")
+	}
+
+	revision, err := h.blogService.GetRevision(currentActor(c), uint(id), number)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("", revision.ToResponse()))
+}
+func (h *BlogController) DiffRevisions(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid blog ID")
+	}
+
+	var query models.BlogRevisionDiffQuery
+	if err := parseQuery(c, &query); err != nil {
+		return err
+	}
+
+	diff, err := h.blogService.DiffRevisions(currentActor(c), uint(id), query)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("", diff))
+}
+func (h *BlogController) RestoreRevision(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid blog ID")
+	}
+	number, err := strconv.Atoi(c.Params("rev"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid revision number")
+	}
+
+	blog, err := h.blogService.RestoreRevision(currentActor(c), uint(id), number)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(fiber.Map{"message": "Blog deleted successfully"})
+	return c.JSON(response.Success("Revision restored successfully", blog.ToResponse()))
 }
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSlugLocation(t *testing.T) {
+	app := fiber.New()
+	api := app.Group("/api")
+	api.Get("/blogs/slug/:slug", func(c *fiber.Ctx) error {
+		location, err := slugLocation(c, "new-slug")
+		if err != nil {
+			return err
+		}
+		return c.Redirect(location, fiber.StatusMovedPermanently)
+	}).Name(BlogSlugRoute)
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/api/blogs/slug/old-slug", "/api/blogs/slug/new-slug"},
+		{"/api/blogs/slug/old-slug?ref=feed&page=2", "/api/blogs/slug/new-slug?ref=feed&page=2"},
+		{"/api/blogs/slug/old%2Dslug/", "/api/blogs/slug/new-slug"},
+	}
+
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.target, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusMovedPermanently {
+			t.Errorf("%s: status %d, want 301", tt.target, resp.StatusCode)
+		}
+		if got := resp.Header.Get(fiber.HeaderLocation); got != tt.want {
+			t.Errorf("%s: Location %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
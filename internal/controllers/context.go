@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"strings"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// currentActor builds the service-level actor from the locals set by AuthMiddleware
+func currentActor(c *fiber.Ctx) services.Actor {
+	userID, _ := c.Locals("userID").(uint)
+	role, _ := c.Locals("role").(models.Role)
+	scopes, _ := c.Locals("scopes").([]models.Permission)
+	return services.Actor{
+		UserID: userID,
+		Role:   role,
+		Scopes: scopes,
+	}
+}
+
+// clientMeta captures where the request came from for throttling and auditing
+func clientMeta(c *fiber.Ctx) services.ClientMeta {
+	return services.ClientMeta{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
+// paginationDefaults clamps page and limit to sane values
+func paginationDefaults(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return page, limit
+}
+
+// parseBody decodes the request body into req and enforces its validate tags
+func parseBody(c *fiber.Ctx, req interface{}) error {
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	return validateRequest(c, req)
+}
+
+// parseQuery decodes the query string into req and enforces its validate tags
+func parseQuery(c *fiber.Ctx, req interface{}) error {
+	if err := c.QueryParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	return validateRequest(c, req)
+}
+
+// formList collects a list sent in a form either as repeated fields or as
+// one comma-separated value. The flag reports whether the field was sent at
+// all, so an empty list can be told apart from leaving the list alone.
+func formList(c *fiber.Ctx, key string) ([]string, bool) {
+	var raw []string
+	if form, err := c.MultipartForm(); err == nil {
+		values, sent := form.Value[key]
+		if !sent {
+			return nil, false
+		}
+		raw = values
+	} else {
+		args := c.Request().PostArgs()
+		if !args.Has(key) {
+			return nil, false
+		}
+		for _, value := range args.PeekMulti(key) {
+			raw = append(raw, string(value))
+		}
+	}
+
+	list := []string{}
+	for _, value := range raw {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list, true
+}
+
+// formField returns a form value and whether the field was sent at all
+func formField(c *fiber.Ctx, key string) (string, bool) {
+	if form, err := c.MultipartForm(); err == nil {
+		values, sent := form.Value[key]
+		if !sent || len(values) == 0 {
+			return "", sent
+		}
+		return values[0], true
+	}
+	args := c.Request().PostArgs()
+	return string(args.Peek(key)), args.Has(key)
+}
+
+// validateRequest enforces the validate tags of a request that was built by
+// hand, e.g. from multipart form values
+func validateRequest(c *fiber.Ctx, req interface{}) error {
+	return validation.Struct(req, requestLocale(c))
+}
+
+func requestLocale(c *fiber.Ctx) string {
+	return validation.Locale(c.Get(fiber.HeaderAcceptLanguage))
+}
@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type JWKSController struct{}
+
+func NewJWKSController() *JWKSController {
+	return &JWKSController{}
+}
+
+// GetJWKS publishes the access token verification keys so other services
+// can validate our tokens without sharing a secret
+func (h *JWKSController) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(services.JWKS())
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MFAController struct {
+	mfaService *services.MFAService
+}
+
+func NewMFAController(cfg *config.Config) *MFAController {
+	return &MFAController{
+		mfaService: services.NewMFAService(cfg),
+	}
+}
+
+func (ctrl *MFAController) SetupTOTP(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	result, err := ctrl.mfaService.SetupTOTP(userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Scan the QR code with your authenticator app, then confirm with a code", result))
+}
+
+func (ctrl *MFAController) ConfirmTOTP(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.MFACodeRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	result, err := ctrl.mfaService.ConfirmTOTP(userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Two-factor authentication enabled. Store these recovery codes somewhere safe", result))
+}
+
+func (ctrl *MFAController) DisableTOTP(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.DisableMFARequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := ctrl.mfaService.DisableTOTP(userID, req.Password, req.Code); err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Two-factor authentication disabled", nil))
+}
+
+func (ctrl *MFAController) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.MFACodeRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	result, err := ctrl.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Recovery codes regenerated. Previous codes no longer work", result))
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OIDCController struct {
+	oidcService *services.OIDCService
+}
+
+func NewOIDCController(cfg *config.Config) *OIDCController {
+	return &OIDCController{
+		oidcService: services.NewOIDCService(cfg),
+	}
+}
+
+func (h *OIDCController) GetProviders(c *fiber.Ctx) error {
+	return c.JSON(response.Success("", h.oidcService.Providers()))
+}
+
+func (h *OIDCController) StartLogin(c *fiber.Ctx) error {
+	result, err := h.oidcService.StartLogin(c.UserContext(), c.Params("provider"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("", result))
+}
+
+func (h *OIDCController) Callback(c *fiber.Ctx) error {
+	req, err := parseOIDCCallback(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.oidcService.CompleteLogin(c.UserContext(), c.Params("provider"), req, clientMeta(c))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(loginResponse(result))
+}
+
+func (h *OIDCController) GetIdentities(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	identities, err := h.oidcService.GetIdentities(userID)
+	if err != nil {
+		return err
+	}
+
+	responses := make([]models.UserIdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		responses = append(responses, identity.ToResponse())
+	}
+
+	return c.JSON(response.Success("", responses))
+}
+
+func (h *OIDCController) StartLink(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	result, err := h.oidcService.StartLink(c.UserContext(), c.Params("provider"), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("", result))
+}
+
+func (h *OIDCController) CompleteLink(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req, err := parseOIDCCallback(c)
+	if err != nil {
+		return err
+	}
+
+	identity, err := h.oidcService.CompleteLink(c.UserContext(), c.Params("provider"), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Provider linked successfully", identity.ToResponse()))
+}
+
+func (h *OIDCController) Unlink(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	if err := h.oidcService.Unlink(userID, c.Params("provider")); err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Provider unlinked successfully", nil))
+}
+
+// parseOIDCCallback reads code and state from the query string when the
+// provider redirects straight to the API, or from the body otherwise
+func parseOIDCCallback(c *fiber.Ctx) (models.OIDCCallbackRequest, error) {
+	var req models.OIDCCallbackRequest
+	if c.Method() == fiber.MethodGet {
+		err := parseQuery(c, &req)
+		return req, err
+	}
+	err := parseBody(c, &req)
+	return req, err
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionController struct {
+	sessionService *services.SessionService
+}
+
+func NewSessionController(cfg *config.Config) *SessionController {
+	return &SessionController{
+		sessionService: services.NewSessionService(cfg),
+	}
+}
+
+func (h *SessionController) GetSessions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	currentSessionID, _ := c.Locals("sessionID").(string)
+
+	sessions, err := h.sessionService.GetSessions(userID)
+	if err != nil {
+		return err
+	}
+
+	responses := make([]models.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, session.ToResponse(currentSessionID))
+	}
+
+	return c.JSON(response.Success("", responses))
+}
+
+func (h *SessionController) RevokeSession(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	if err := h.sessionService.RevokeSession(userID, c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Session revoked successfully", nil))
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"strconv"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TaxonomyController struct {
+	taxonomyService *services.TaxonomyService
+}
+
+func NewTaxonomyController(cfg *config.Config) *TaxonomyController {
+	return &TaxonomyController{
+		taxonomyService: services.NewTaxonomyService(cfg),
+	}
+}
+
+func (h *TaxonomyController) GetTags(c *fiber.Ctx) error {
+	var params models.TagQueryParams
+	if err := parseQuery(c, &params); err != nil {
+		return err
+	}
+	params.Page, params.Limit = paginationDefaults(params.Page, params.Limit)
+
+	tags, total, err := h.taxonomyService.GetTags(params)
+	if err != nil {
+		return err
+	}
+
+	responses := []models.TagCountResponse{}
+	for _, tag := range tags {
+		responses = append(responses, tag.ToCountResponse())
+	}
+
+	return c.JSON(response.Paginated(responses, params.Page, params.Limit, total))
+}
+
+func (h *TaxonomyController) RenameTag(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tag ID")
+	}
+
+	var req models.RenameTagRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	tag, err := h.taxonomyService.RenameTag(currentActor(c), clientMeta(c), uint(id), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Tag renamed successfully", tag.ToResponse()))
+}
+
+func (h *TaxonomyController) MergeTags(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tag ID")
+	}
+
+	var req models.MergeTagRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	tag, err := h.taxonomyService.MergeTags(currentActor(c), clientMeta(c), uint(id), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Tags merged successfully", tag.ToResponse()))
+}
+
+func (h *TaxonomyController) GetCategories(c *fiber.Ctx) error {
+	categories, err := h.taxonomyService.GetCategories()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("", models.CategoryTree(categories)))
+}
+
+func (h *TaxonomyController) CreateCategory(c *fiber.Ctx) error {
+	var req models.CreateCategoryRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	category, err := h.taxonomyService.CreateCategory(currentActor(c), clientMeta(c), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success("Category created successfully", category.ToResponse()))
+}
+
+func (h *TaxonomyController) UpdateCategory(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category ID")
+	}
+
+	var req models.UpdateCategoryRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	category, err := h.taxonomyService.UpdateCategory(currentActor(c), clientMeta(c), uint(id), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Category updated successfully", category.ToResponse()))
+}
+
+func (h *TaxonomyController) DeleteCategory(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category ID")
+	}
+
+	if err := h.taxonomyService.DeleteCategory(currentActor(c), clientMeta(c), uint(id)); err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Category deleted successfully", nil))
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UserController struct {
+	userService *services.UserService
+}
+
+func NewUserController(cfg *config.Config) (*UserController, error) {
+	userService, err := services.NewUserService(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &UserController{
+		userService: userService,
+	}, nil
+}
+
+func (h *UserController) GetMe(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	user, err := h.userService.GetProfile(userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("", user.ToResponse()))
+}
+
+func (h *UserController) UpdateMe(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.UpdateProfileRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	file, err := c.FormFile("avatar")
+	if err == nil {
+		req.Avatar = file
+	}
+
+	user, err := h.userService.UpdateProfile(userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Profile updated successfully", user.ToResponse()))
+}
+
+func (h *UserController) ChangePassword(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.ChangePasswordRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.userService.ChangePassword(userID, req); err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Password changed successfully, please log in again", nil))
+}
+
+func (h *UserController) DeleteMe(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	sessionID, _ := c.Locals("sessionID").(string)
+
+	var req models.DeleteAccountRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.userService.DeleteAccount(userID, sessionID, req); err != nil {
+		return err
+	}
+
+	return c.JSON(response.Success("Account deleted successfully", nil))
+}
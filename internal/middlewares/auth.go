@@ -2,42 +2,97 @@ package middlewares
 
 import (
 	"strings"
+	"time"
 
 	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
 	"go-fiber-boilerplate/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func AuthMiddleware(cfg *config.Config) fiber.Handler {
+	apiKeyService := services.NewAPIKeyService(cfg)
+
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Authorization header required",
-			})
+			return services.ErrAuthHeaderRequired
 		}
 
 		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid authorization header format",
-			})
+		if len(tokenParts) != 2 || (tokenParts[0] != "Bearer" && tokenParts[0] != "ApiKey") {
+			return services.ErrInvalidAuthHeader
+		}
+
+		if tokenParts[0] == "ApiKey" {
+			return authenticateAPIKey(c, apiKeyService, tokenParts[1])
 		}
 
 		token := tokenParts[1]
-		claims, err := utils.ValidateJWT(token, cfg.JWTSecret)
+		claims, err := utils.ValidateJWT(token, services.JWTConfig(cfg))
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token",
-			})
+			return services.ErrInvalidToken
+		}
+
+		var issuedAt, expiresAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		if claims.ExpiresAt != nil {
+			expiresAt = claims.ExpiresAt.Time
 		}
 
+		if services.GetRevocationStore().IsRevoked(claims.ID, claims.UserID, issuedAt) {
+			return services.ErrTokenRevoked
+		}
+
+		if services.GetRevocationStore().IsSessionRevoked(claims.SessionID) {
+			return services.ErrSessionRevoked
+		}
+		services.TouchSession(claims.SessionID)
+
 		// Store user info in context
 		c.Locals("userID", claims.UserID)
 		c.Locals("email", claims.Email)
+		c.Locals("role", models.Role(claims.Role))
+		c.Locals("tokenID", claims.ID)
+		c.Locals("sessionID", claims.SessionID)
+		c.Locals("tokenExpiresAt", expiresAt)
+		c.Locals("authMethod", "jwt")
 
 		return c.Next()
 	}
 }
 
+// OptionalAuthMiddleware lets anonymous requests through but authenticates
+// any request that sends credentials, so handlers can tailor what they show
+func OptionalAuthMiddleware(cfg *config.Config) fiber.Handler {
+	auth := AuthMiddleware(cfg)
+
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			return c.Next()
+		}
+		return auth(c)
+	}
+}
+
+// authenticateAPIKey sets the same locals as a JWT so handlers work unchanged
+func authenticateAPIKey(c *fiber.Ctx, apiKeyService *services.APIKeyService, rawKey string) error {
+	user, apiKey, err := apiKeyService.Authenticate(rawKey)
+	if err != nil {
+		return err
+	}
+
+	c.Locals("userID", user.ID)
+	c.Locals("email", user.Email)
+	c.Locals("role", user.Role)
+	c.Locals("scopes", apiKey.ScopeList())
+	c.Locals("apiKeyID", apiKey.ID)
+	c.Locals("authMethod", "api_key")
+
+	return c.Next()
+}
+
@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAuthFailuresAreProblems(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) }
+	asAPIKey := func(c *fiber.Ctx) error {
+		c.Locals("authMethod", "api_key")
+		c.Locals("role", models.RoleAuthor)
+		c.Locals("scopes", []models.Permission{})
+		return c.Next()
+	}
+
+	app.Get("/private", AuthMiddleware(&config.Config{}), ok)
+	app.Get("/session-only", asAPIKey, RequireSessionAuth(), ok)
+	app.Get("/scoped", asAPIKey, RequirePermission(models.PermissionBlogCreate), ok)
+
+	tests := []struct {
+		target string
+		header string
+		status int
+		code   string
+	}{
+		{"/private", "", fiber.StatusUnauthorized, "authorization_required"},
+		{"/private", "Basic abc", fiber.StatusUnauthorized, "invalid_authorization_header"},
+		{"/private", "Bearer not-a-jwt", fiber.StatusUnauthorized, "invalid_token"},
+		{"/private", "ApiKey malformed", fiber.StatusUnauthorized, "invalid_api_key"},
+		{"/session-only", "", fiber.StatusForbidden, "session_auth_required"},
+		{"/scoped", "", fiber.StatusForbidden, "insufficient_permissions"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(fiber.MethodGet, tt.target, nil)
+		if tt.header != "" {
+			req.Header.Set(fiber.HeaderAuthorization, tt.header)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var problem response.Problem
+		if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+			t.Fatalf("%s %q: %v", tt.target, tt.header, err)
+		}
+		if resp.StatusCode != tt.status || problem.Code != tt.code {
+			t.Errorf("%s %q: %d %s, want %d %s", tt.target, tt.header, resp.StatusCode, problem.Code, tt.status, tt.code)
+		}
+		if got := resp.Header.Get(fiber.HeaderContentType); got != response.ProblemContentType {
+			t.Errorf("%s %q: Content-Type %q, want %q", tt.target, tt.header, got, response.ProblemContentType)
+		}
+	}
+}
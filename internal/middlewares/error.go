@@ -1,23 +1,96 @@
 package middlewares
 
 import (
+	"errors"
 	"log"
+	"net/http"
+	"strings"
+
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/internal/validation"
+	"go-fiber-boilerplate/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+var kindStatus = map[services.ErrorKind]int{
+	services.KindInternal:        fiber.StatusInternalServerError,
+	services.KindInvalid:         fiber.StatusBadRequest,
+	services.KindUnauthenticated: fiber.StatusUnauthorized,
+	services.KindForbidden:       fiber.StatusForbidden,
+	services.KindNotFound:        fiber.StatusNotFound,
+	services.KindConflict:        fiber.StatusConflict,
+	services.KindRateLimited:     fiber.StatusTooManyRequests,
+	services.KindUnavailable:     fiber.StatusBadGateway,
+}
+
+// ErrorHandler turns every error a handler returns into an RFC 7807
+// problem+json response with a stable error code
 func ErrorHandler(c *fiber.Ctx, err error) error {
-	code := fiber.StatusInternalServerError
+	problem := problemFor(c, err)
+	problem.Instance = c.OriginalURL()
 
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
+	if problem.Status >= fiber.StatusInternalServerError {
+		// The cause a domain error wraps never reaches the client, but belongs in the log
+		var domainErr *services.Error
+		if errors.As(err, &domainErr) && domainErr.Err != nil {
+			log.Printf("Error: %s %s: %v: %v", c.Method(), c.OriginalURL(), err, domainErr.Err)
+		} else {
+			log.Printf("Error: %s %s: %v", c.Method(), c.OriginalURL(), err)
+		}
 	}
 
-	log.Printf("Error: %v", err)
+	return c.Status(problem.Status).JSON(problem, response.ProblemContentType)
+}
+
+func problemFor(c *fiber.Ctx, err error) response.Problem {
+	var (
+		domainErr *services.Error
+		policyErr *services.PasswordPolicyError
+		fieldErrs validation.Errors
+		fiberErr  *fiber.Error
+	)
+
+	switch {
+	case errors.As(err, &fieldErrs):
+		locale := validation.Locale(c.Get(fiber.HeaderAcceptLanguage))
+		problem := response.NewProblem(fiber.StatusUnprocessableEntity, "validation_failed", validation.Message(locale, "validation_failed"))
+		problem.Fields = fieldErrs
+		return problem
+
+	case errors.As(err, &policyErr):
+		problem := response.NewProblem(fiber.StatusUnprocessableEntity, "password_policy", policyErr.Error())
+		problem.Fields = policyErr.Violations
+		return problem
 
-	return c.Status(code).JSON(fiber.Map{
-		"error":   true,
-		"message": err.Error(),
-	})
+	case errors.As(err, &domainErr):
+		status, ok := kindStatus[domainErr.Kind]
+		if !ok {
+			status = fiber.StatusInternalServerError
+		}
+		return response.NewProblem(status, domainErr.Code, domainErr.Message)
+
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NewProblem(fiber.StatusNotFound, "not_found", "resource not found")
+
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		// A unique constraint caught what a service's own checks could not, e.g. a race
+		return response.NewProblem(fiber.StatusConflict, "conflict", "resource already exists")
+
+	case errors.As(err, &fiberErr):
+		return response.NewProblem(fiberErr.Code, statusCode(fiberErr.Code), fiberErr.Message)
+	}
+
+	// Anything else is unexpected and may carry internals, so it is only logged
+	return response.NewProblem(fiber.StatusInternalServerError, "internal_error", "an unexpected error occurred")
 }
 
+// statusCode derives an error code from a status, e.g. 404 -> not_found
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"fmt"
 	"net/http"
 	"path/filepath"
 	"strings"
@@ -18,16 +19,12 @@ func (u *UploaderMiddleware) Upload(maxSizeMB int) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		file, err := c.FormFile("image")
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "No file uploaded",
-			})
+			return fiber.NewError(fiber.StatusBadRequest, "No file uploaded")
 		}
 
 		maxSize := int64(maxSizeMB * 1024 * 1024)
 		if file.Size > maxSize {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "File too large (max " + string(rune(maxSizeMB)) + "MB)",
-			})
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("File too large (max %dMB)", maxSizeMB))
 		}
 
 		c.Locals("uploadedFile", file)
@@ -39,25 +36,19 @@ func (u *UploaderMiddleware) FileFilter(allowedTypes []string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		file, err := c.FormFile("image")
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "No file uploaded",
-			})
+			return fiber.NewError(fiber.StatusBadRequest, "No file uploaded")
 		}
 
 		src, err := file.Open()
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to open file",
-			})
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to open file")
 		}
 		defer src.Close()
 
 		buffer := make([]byte, 512)
 		_, err = src.Read(buffer)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to read file",
-			})
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to read file")
 		}
 
 		mimeType := http.DetectContentType(buffer)
@@ -71,9 +62,7 @@ func (u *UploaderMiddleware) FileFilter(allowedTypes []string) fiber.Handler {
 		}
 
 		if !isAllowed {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "File type " + mimeType + " is not allowed",
-			})
+			return fiber.NewError(fiber.StatusBadRequest, "File type "+mimeType+" is not allowed")
 		}
 
 		ext := strings.ToLower(filepath.Ext(file.Filename))
@@ -87,9 +76,7 @@ func (u *UploaderMiddleware) FileFilter(allowedTypes []string) fiber.Handler {
 		}
 
 		if !isValidExt {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Invalid file extension",
-			})
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid file extension")
 		}
 
 		c.Locals("uploadedFile", file)
@@ -121,32 +108,24 @@ func (u *UploaderMiddleware) ImageUpload(maxSizeMB int, allowedTypes []string) f
 	return func(c *fiber.Ctx) error {
 		file, err := c.FormFile("image")
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "No file uploaded",
-			})
+			return fiber.NewError(fiber.StatusBadRequest, "No file uploaded")
 		}
 
 		maxSize := int64(maxSizeMB * 1024 * 1024)
 		if file.Size > maxSize {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "File too large (max " + string(rune(maxSizeMB)) + "MB)",
-			})
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("File too large (max %dMB)", maxSizeMB))
 		}
 
 		src, err := file.Open()
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to open file",
-			})
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to open file")
 		}
 		defer src.Close()
 
 		buffer := make([]byte, 512)
 		_, err = src.Read(buffer)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to read file",
-			})
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to read file")
 		}
 
 		mimeType := http.DetectContentType(buffer)
@@ -160,9 +139,7 @@ func (u *UploaderMiddleware) ImageUpload(maxSizeMB int, allowedTypes []string) f
 		}
 
 		if !isAllowed {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "File type " + mimeType + " is not allowed",
-			})
+			return fiber.NewError(fiber.StatusBadRequest, "File type "+mimeType+" is not allowed")
 		}
 
 		ext := strings.ToLower(filepath.Ext(file.Filename))
@@ -176,9 +153,7 @@ func (u *UploaderMiddleware) ImageUpload(maxSizeMB int, allowedTypes []string) f
 		}
 
 		if !isValidExt {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Invalid file extension",
-			})
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid file extension")
 		}
 
 		c.Locals("uploadedFile", file)
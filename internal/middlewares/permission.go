@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission allows the request only if the authenticated user's role
+// grants every listed permission, and for API keys only if the key was scoped
+// for them too. It must run after AuthMiddleware.
+func RequirePermission(permissions ...models.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, ok := c.Locals("role").(models.Role)
+		if !ok {
+			return services.ErrInsufficientPermissions
+		}
+
+		scopes, scoped := c.Locals("scopes").([]models.Permission)
+
+		for _, permission := range permissions {
+			if !role.HasPermission(permission) || (scoped && !hasScope(scopes, permission)) {
+				return services.ErrInsufficientPermissions
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireSessionAuth rejects API key requests on endpoints that manage the
+// account itself, such as changing the password or creating more API keys
+func RequireSessionAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals("authMethod") == "api_key" {
+			return services.ErrSessionAuthRequired
+		}
+		return c.Next()
+	}
+}
+
+func hasScope(scopes []models.Permission, permission models.Permission) bool {
+	for _, scope := range scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
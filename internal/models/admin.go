@@ -0,0 +1,19 @@
+package models
+
+type AdminUserQueryParams struct {
+	Page     int    `query:"page"`
+	Limit    int    `query:"limit"`
+	Search   string `query:"search"`
+	Role     string `query:"role" validate:"omitempty,oneof=admin editor author"`
+	IsActive *bool  `query:"is_active"`
+}
+
+// UpdateUserStatusRequest represents the request to activate or deactivate a user
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active" validate:"required"`
+}
+
+// UpdateUserRoleRequest represents the request to change a user's role
+type UpdateUserRoleRequest struct {
+	Role Role `json:"role" validate:"required,oneof=admin editor author"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey represents a personal API key for machine clients. Only the SHA-256
+// of the secret part is stored; Prefix is kept in clear text for lookup.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null;size:100"`
+	Prefix     string     `json:"prefix" gorm:"not null;size:16;uniqueIndex"`
+	KeyHash    string     `json:"-" gorm:"not null;size:64"`
+	Scopes     string     `json:"scopes" gorm:"not null;size:255"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type APIKeyResponse struct {
+	ID         uint         `json:"id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	Scopes     []Permission `json:"scopes"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	LastUsedAt *time.Time   `json:"last_used_at"`
+	RevokedAt  *time.Time   `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string       `json:"name" validate:"required,max=100"`
+	Scopes        []Permission `json:"scopes" validate:"required"`
+	ExpiresInDays int          `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+// CreateAPIKeyResponse carries the full key, which is only ever shown once
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// ScopeList returns the permissions the key was granted
+func (k *APIKey) ScopeList() []Permission {
+	scopes := []Permission{}
+	for _, scope := range strings.Fields(k.Scopes) {
+		scopes = append(scopes, Permission(scope))
+	}
+	return scopes
+}
+
+func (k *APIKey) ToResponse() APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.ScopeList(),
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
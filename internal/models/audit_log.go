@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// AuditLog records an administrative action for later review
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ActorID    uint      `json:"actor_id" gorm:"not null;index"`
+	Action     string    `json:"action" gorm:"not null;size:64;index"`
+	TargetType string    `json:"target_type" gorm:"not null;size:32"`
+	TargetID   uint      `json:"target_id" gorm:"index"`
+	Details    string    `json:"details" gorm:"type:text"`
+	IP         string    `json:"ip" gorm:"size:64"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+type AuditLogQueryParams struct {
+	Page     int    `query:"page"`
+	Limit    int    `query:"limit"`
+	ActorID  uint   `query:"actor_id"`
+	TargetID uint   `query:"target_id"`
+	Action   string `query:"action"`
+}
@@ -8,67 +8,134 @@ import (
 )
 
 type Blog struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Title     string         `json:"title" gorm:"not null;size:255"`
-	Content   string         `json:"content" gorm:"not null;type:text"`
-	Slug      string         `json:"slug" gorm:"unique;not null;size:255"`
-	Published bool           `json:"published" gorm:"default:false"`
-	ImageURL  string         `json:"image_url" gorm:"size:255"`
-	ImageID   string         `json:"image_id" gorm:"size:255"`
-	UserID    uint           `json:"userId" gorm:"not null"`
-	User      User           `json:"user" gorm:"foreignKey:UserID"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Title     string `json:"title" gorm:"not null;size:255"`
+	Content   string `json:"content" gorm:"not null;type:text"`
+	Slug      string `json:"slug" gorm:"not null;size:255;uniqueIndex:idx_blogs_slug,where:deleted_at IS NULL"`
+	Published bool   `json:"published" gorm:"default:false"`
+	// PublishAt and UnpublishAt are pending scheduled changes, cleared once
+	// carried out. PublishedAt is set the first time the blog goes live.
+	PublishAt   *time.Time     `json:"publish_at" gorm:"index"`
+	UnpublishAt *time.Time     `json:"unpublish_at" gorm:"index"`
+	PublishedAt *time.Time     `json:"published_at"`
+	ImageURL    string         `json:"image_url" gorm:"size:255"`
+	ImageID     string         `json:"image_id" gorm:"size:255"`
+	UserID      uint           `json:"userId" gorm:"not null"`
+	User        User           `json:"user" gorm:"foreignKey:UserID"`
+	CategoryID  *uint          `json:"category_id" gorm:"index"`
+	Category    *Category      `json:"category" gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL"`
+	Tags        []Tag          `json:"tags" gorm:"many2many:blog_tags"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Only filled in by full-text searches, never stored
+	SearchRank float64 `json:"-" gorm:"->;-:migration"`
+	Snippet    string  `json:"-" gorm:"->;-:migration"`
+}
+
+// BlogSlug remembers a slug a blog used to have, so old links keep working
+type BlogSlug struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BlogID    uint      `json:"blog_id" gorm:"not null;index"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex;not null;size:255"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type BlogResponse struct {
-	ID        uint         `json:"id"`
-	Title     string       `json:"title"`
-	Content   string       `json:"content"`
-	Slug      string       `json:"slug"`
-	Published bool         `json:"published"`
-	UserID    uint         `json:"userId"`
-	User      UserResponse `json:"user"`
-	ImageURL  string       `json:"image_url"`
-	CreatedAt time.Time    `json:"created_at"`
-	UpdatedAt time.Time    `json:"updated_at"`
+	ID          uint              `json:"id"`
+	Title       string            `json:"title"`
+	Content     string            `json:"content"`
+	Slug        string            `json:"slug"`
+	Published   bool              `json:"published"`
+	PublishAt   *time.Time        `json:"publish_at,omitempty"`
+	UnpublishAt *time.Time        `json:"unpublish_at,omitempty"`
+	PublishedAt *time.Time        `json:"published_at"`
+	UserID      uint              `json:"userId"`
+	User        UserResponse      `json:"user"`
+	Category    *CategoryResponse `json:"category"`
+	Tags        []TagResponse     `json:"tags"`
+	ImageURL    string            `json:"image_url"`
+	Snippet     string            `json:"snippet,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
 }
 
 type CreateBlogRequest struct {
-	Title     string          `json:"title" validate:"required,min=1,max=255"`
-	Content   string          `json:"content" validate:"required,min=1"`
-	Published bool            `json:"published"`
-	UserID    uint            `json:"user_id"`
-	Image     *multipart.FileHeader `json:"image" form:"image"`
+	Title       string                `json:"title" validate:"required,min=1,max=255"`
+	Content     string                `json:"content" validate:"required,min=1"`
+	Slug        string                `json:"slug" validate:"omitempty,max=255,slug"`
+	Published   bool                  `json:"published"`
+	PublishAt   string                `json:"publish_at" validate:"omitempty,date"`
+	UnpublishAt string                `json:"unpublish_at" validate:"omitempty,date"`
+	Tags        []string              `json:"tags" validate:"max=10"`
+	Category    string                `json:"category"`
+	UserID      uint                  `json:"user_id"`
+	Image       *multipart.FileHeader `json:"image" form:"image"`
 }
 
 type UpdateBlogRequest struct {
-	Title     string                `json:"title" validate:"required,min=1,max=255"`
-	Content   string                `json:"content" validate:"required,min=1"`
-	Published *bool                 `json:"published"`
-	Image     *multipart.FileHeader `json:"image" form:"image"`
+	Title       string `json:"title" validate:"omitempty,max=255"`
+	Content     string `json:"content"`
+	Slug        string `json:"slug" validate:"omitempty,max=255,slug"`
+	Published   *bool  `json:"published"`
+	PublishAt   string `json:"publish_at" validate:"omitempty,date"`
+	UnpublishAt string `json:"unpublish_at" validate:"omitempty,date"`
+	// Tags and Category are left alone when nil; empty values clear them
+	Tags     *[]string             `json:"tags" validate:"omitempty,max=10"`
+	Category *string               `json:"category"`
+	Image    *multipart.FileHeader `json:"image" form:"image"`
 }
 
+// Blog list sort orders. Relevance only applies to searches.
+const (
+	BlogSortRelevance = "relevance"
+	BlogSortNewest    = "newest"
+	BlogSortOldest    = "oldest"
+	BlogSortTitle     = "title"
+)
+
 type BlogQueryParams struct {
 	Page      int    `query:"page"`
 	Limit     int    `query:"limit"`
-	Search    string `query:"search"`
+	Search    string `query:"search" validate:"omitempty,max=200"`
 	Published *bool  `query:"published"`
 	UserID    uint   `query:"user_id"`
+	From      string `query:"from" validate:"omitempty,date"`
+	To        string `query:"to" validate:"omitempty,date"`
+	Sort      string `query:"sort" validate:"omitempty,oneof=relevance newest oldest title"`
+	Tag       string `query:"tag" validate:"omitempty,max=64"`
+	Category  string `query:"category" validate:"omitempty,max=100"`
 }
 
 func (u *Blog) ToResponse() BlogResponse {
+	var category *CategoryResponse
+	if u.Category != nil {
+		response := u.Category.ToResponse()
+		category = &response
+	}
+
+	tags := []TagResponse{}
+	for _, tag := range u.Tags {
+		tags = append(tags, tag.ToResponse())
+	}
+
 	return BlogResponse{
-		ID:        u.ID,
-		Title:     u.Title,
-		Content:   u.Content,
-		Slug:      u.Slug,
-		Published: u.Published,
-		UserID:    u.UserID,
-		User:      u.User.ToResponse(),
-		ImageURL:  u.ImageURL,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:          u.ID,
+		Title:       u.Title,
+		Content:     u.Content,
+		Slug:        u.Slug,
+		Published:   u.Published,
+		PublishAt:   u.PublishAt,
+		UnpublishAt: u.UnpublishAt,
+		PublishedAt: u.PublishedAt,
+		UserID:      u.UserID,
+		User:        u.User.ToResponse(),
+		Category:    category,
+		Tags:        tags,
+		ImageURL:    u.ImageURL,
+		Snippet:     u.Snippet,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
 	}
 }
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"go-fiber-boilerplate/utils"
+)
+
+// BlogRevision is an immutable snapshot of a blog as saved. Number counts up
+// from 1 per blog.
+type BlogRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BlogID    uint      `json:"blog_id" gorm:"not null;uniqueIndex:idx_blog_revisions_blog_number"`
+	Number    int       `json:"number" gorm:"not null;uniqueIndex:idx_blog_revisions_blog_number"`
+	Title     string    `json:"title" gorm:"not null;size:255"`
+	Content   string    `json:"content" gorm:"not null;type:text"`
+	Published bool      `json:"published"`
+	EditorID  uint      `json:"editor_id" gorm:"not null;index"`
+	Editor    User      `json:"editor" gorm:"foreignKey:EditorID"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BlogRevisionResponse struct {
+	Number    int          `json:"number"`
+	Title     string       `json:"title"`
+	Content   string       `json:"content,omitempty"`
+	Published bool         `json:"published"`
+	Editor    UserResponse `json:"editor"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// Revision diff granularities
+const (
+	DiffModeLine = "line"
+	DiffModeWord = "word"
+)
+
+type BlogRevisionDiffQuery struct {
+	From int    `query:"from" validate:"required,min=1"`
+	To   int    `query:"to" validate:"required,min=1"`
+	Mode string `query:"mode" validate:"omitempty,oneof=line word"`
+}
+
+type BlogRevisionDiffResponse struct {
+	From    BlogRevisionResponse `json:"from"`
+	To      BlogRevisionResponse `json:"to"`
+	Mode    string               `json:"mode"`
+	Title   []utils.DiffChunk    `json:"title"`
+	Content []utils.DiffChunk    `json:"content"`
+}
+
+// ToResponse includes the content; ToSummary leaves it out for listings
+func (r *BlogRevision) ToResponse() BlogRevisionResponse {
+	response := r.ToSummary()
+	response.Content = r.Content
+	return response
+}
+
+func (r *BlogRevision) ToSummary() BlogRevisionResponse {
+	return BlogRevisionResponse{
+		Number:    r.Number,
+		Title:     r.Title,
+		Published: r.Published,
+		Editor:    r.Editor.ToResponse(),
+		CreatedAt: r.CreatedAt,
+	}
+}
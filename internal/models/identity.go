@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// UserIdentity links a user to an account at an external OpenID Connect
+// provider, identified by the provider's stable subject claim
+type UserIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_identities_user_provider"`
+	Provider  string    `json:"provider" gorm:"not null;size:50;uniqueIndex:idx_user_identities_user_provider;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject   string    `json:"-" gorm:"not null;size:255;uniqueIndex:idx_user_identities_provider_subject"`
+	Email     string    `json:"email" gorm:"size:255"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OIDCAuthRequest holds the state, nonce and PKCE verifier of an
+// authorization request until the provider redirects back. It is single-use.
+type OIDCAuthRequest struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	StateHash    string    `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	Provider     string    `json:"provider" gorm:"not null;size:50"`
+	Nonce        string    `json:"-" gorm:"not null;size:64"`
+	CodeVerifier string    `json:"-" gorm:"not null;size:128"`
+	LinkUserID   *uint     `json:"link_user_id" gorm:"index"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// UserIdentityResponse represents a linked provider in the user's profile
+type UserIdentityResponse struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OIDCAuthorizationResponse is returned when starting a login or link flow
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// OIDCCallbackRequest carries the parameters the provider redirected back with
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" query:"code" validate:"required"`
+	State string `json:"state" query:"state" validate:"required"`
+}
+
+// ToResponse converts UserIdentity to UserIdentityResponse
+func (i *UserIdentity) ToResponse() UserIdentityResponse {
+	return UserIdentityResponse{
+		Provider:  i.Provider,
+		Email:     i.Email,
+		CreatedAt: i.CreatedAt,
+	}
+}
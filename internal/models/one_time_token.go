@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Purposes a OneTimeToken can be issued for
+const (
+	TokenPurposeResetPassword = "reset_password"
+	TokenPurposeMagicLink     = "magic_link"
+)
+
+// OneTimeToken represents a hashed, single-use token emailed to a user.
+// Only the SHA-256 of the token is stored; the raw value lives in the email link.
+type OneTimeToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Purpose   string     `json:"purpose" gorm:"not null;size:32;index"`
+	Email     string     `json:"email" gorm:"not null;size:255"`
+	TokenHash string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
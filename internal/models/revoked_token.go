@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// RevokedToken represents an access token that was revoked before it expired
+type RevokedToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	JTI       string    `json:"jti" gorm:"not null;size:64;uniqueIndex"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserTokenRevocation revokes every access token of a user issued before RevokedBefore
+type UserTokenRevocation struct {
+	UserID        uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	RevokedBefore time.Time `json:"revoked_before" gorm:"not null"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"not null;index"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// LogoutRequest represents the logout request
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,70 @@
+package models
+
+// Role represents the role a user holds
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleAuthor Role = "author"
+)
+
+// Permission represents a single action a role may perform
+type Permission string
+
+const (
+	PermissionBlogCreate     Permission = "blogs:create"
+	PermissionBlogUpdate     Permission = "blogs:update"
+	PermissionBlogDelete     Permission = "blogs:delete"
+	PermissionBlogUpdateAny  Permission = "blogs:update:any"
+	PermissionBlogPublishAny Permission = "blogs:publish:any"
+	PermissionBlogDeleteAny  Permission = "blogs:delete:any"
+	PermissionBlogReadAny    Permission = "blogs:read:any"
+	PermissionUserManage     Permission = "users:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionBlogCreate,
+		PermissionBlogUpdate,
+		PermissionBlogDelete,
+		PermissionBlogUpdateAny,
+		PermissionBlogPublishAny,
+		PermissionBlogDeleteAny,
+		PermissionBlogReadAny,
+		PermissionUserManage,
+	},
+	RoleEditor: {
+		PermissionBlogCreate,
+		PermissionBlogUpdate,
+		PermissionBlogDelete,
+		PermissionBlogUpdateAny,
+		PermissionBlogPublishAny,
+	},
+	RoleAuthor: {
+		PermissionBlogCreate,
+		PermissionBlogUpdate,
+		PermissionBlogDelete,
+	},
+}
+
+// IsValid reports whether the role is one of the known roles
+func (r Role) IsValid() bool {
+	_, exists := rolePermissions[r]
+	return exists
+}
+
+// Permissions returns the permissions granted to the role
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// HasPermission reports whether the role grants the given permission
+func (r Role) HasPermission(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
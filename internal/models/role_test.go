@@ -0,0 +1,53 @@
+package models
+
+import "testing"
+
+func TestRolePermissions(t *testing.T) {
+	all := []Permission{
+		PermissionBlogCreate,
+		PermissionBlogUpdate,
+		PermissionBlogDelete,
+		PermissionBlogUpdateAny,
+		PermissionBlogPublishAny,
+		PermissionBlogDeleteAny,
+		PermissionBlogReadAny,
+		PermissionUserManage,
+	}
+	granted := map[Role]map[Permission]bool{
+		RoleAdmin: {
+			PermissionBlogCreate: true, PermissionBlogUpdate: true, PermissionBlogDelete: true,
+			PermissionBlogUpdateAny: true, PermissionBlogPublishAny: true, PermissionBlogDeleteAny: true,
+			PermissionBlogReadAny: true, PermissionUserManage: true,
+		},
+		RoleEditor: {
+			PermissionBlogCreate: true, PermissionBlogUpdate: true, PermissionBlogDelete: true,
+			PermissionBlogUpdateAny: true, PermissionBlogPublishAny: true,
+		},
+		RoleAuthor: {
+			PermissionBlogCreate: true, PermissionBlogUpdate: true, PermissionBlogDelete: true,
+		},
+		Role("guest"): {},
+		Role(""):      {},
+	}
+
+	for role, want := range granted {
+		for _, permission := range all {
+			if got := role.HasPermission(permission); got != want[permission] {
+				t.Errorf("%q.HasPermission(%s) = %v, want %v", role, permission, got, want[permission])
+			}
+		}
+	}
+}
+
+func TestRoleIsValid(t *testing.T) {
+	for _, role := range []Role{RoleAdmin, RoleEditor, RoleAuthor} {
+		if !role.IsValid() {
+			t.Errorf("%q is not valid", role)
+		}
+	}
+	for _, role := range []Role{"", "guest", "Admin"} {
+		if role.IsValid() {
+			t.Errorf("%q is valid", role)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Session is one signed-in device. Its ID doubles as the refresh token
+// family ID and is embedded as the sid claim of every access token minted
+// for it, so revoking the session kills both.
+type Session struct {
+	ID         string     `json:"id" gorm:"primaryKey;size:64"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	UserAgent  string     `json:"user_agent" gorm:"size:512"`
+	IPAddress  string     `json:"ip_address" gorm:"size:64"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null;index"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// SessionResponse represents a session in the "where am I logged in" list
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// ToResponse converts Session to SessionResponse
+func (s *Session) ToResponse(currentSessionID string) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+		ExpiresAt:  s.ExpiresAt,
+		Current:    s.ID == currentSessionID,
+	}
+}
@@ -0,0 +1,140 @@
+package models
+
+import "time"
+
+// Tag is a free-form label; blogs may carry several
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null;size:64"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex;not null;size:64"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Only filled in by GET /tags, never stored
+	PostCount int64 `json:"-" gorm:"->;-:migration"`
+}
+
+// Category places a blog in a tree; a blog belongs to at most one
+type Category struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null;size:100"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex;not null;size:100"`
+	ParentID  *uint     `json:"parent_id" gorm:"index"`
+	Parent    *Category `json:"-" gorm:"foreignKey:ParentID;constraint:OnDelete:SET NULL"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Only filled in by GET /categories, never stored
+	PostCount int64 `json:"-" gorm:"->;-:migration"`
+}
+
+type TagResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type TagCountResponse struct {
+	TagResponse
+	PostCount int64 `json:"post_count"`
+}
+
+type CategoryResponse struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+type CategoryTreeResponse struct {
+	CategoryResponse
+	PostCount int64                  `json:"post_count"`
+	Children  []CategoryTreeResponse `json:"children"`
+}
+
+type TagQueryParams struct {
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+	Search string `query:"search" validate:"omitempty,max=64"`
+}
+
+// RenameTagRequest renames a tag; the slug follows the name unless given
+type RenameTagRequest struct {
+	Name string `json:"name" validate:"required,max=64"`
+	Slug string `json:"slug" validate:"omitempty,max=64,slug"`
+}
+
+// MergeTagRequest moves every blog of a tag to another and removes the first
+type MergeTagRequest struct {
+	IntoID uint `json:"into_id" validate:"required"`
+}
+
+type CreateCategoryRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Slug     string `json:"slug" validate:"omitempty,max=100,slug"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// UpdateCategoryRequest changes only the fields sent. A parent_id of 0 moves
+// the category to the top level.
+type UpdateCategoryRequest struct {
+	Name     string `json:"name" validate:"omitempty,max=100"`
+	Slug     string `json:"slug" validate:"omitempty,max=100,slug"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+func (t *Tag) ToResponse() TagResponse {
+	return TagResponse{
+		ID:   t.ID,
+		Name: t.Name,
+		Slug: t.Slug,
+	}
+}
+
+func (t *Tag) ToCountResponse() TagCountResponse {
+	return TagCountResponse{
+		TagResponse: t.ToResponse(),
+		PostCount:   t.PostCount,
+	}
+}
+
+func (c *Category) ToResponse() CategoryResponse {
+	return CategoryResponse{
+		ID:       c.ID,
+		Name:     c.Name,
+		Slug:     c.Slug,
+		ParentID: c.ParentID,
+	}
+}
+
+// CategoryTree nests categories under their parents. A category's post count
+// includes the posts of everything below it.
+func CategoryTree(categories []Category) []CategoryTreeResponse {
+	children := map[uint][]Category{}
+	var roots []Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var build func(nodes []Category) []CategoryTreeResponse
+	build = func(nodes []Category) []CategoryTreeResponse {
+		tree := []CategoryTreeResponse{}
+		for _, node := range nodes {
+			branch := CategoryTreeResponse{
+				CategoryResponse: node.ToResponse(),
+				PostCount:        node.PostCount,
+				Children:         build(children[node.ID]),
+			}
+			for _, child := range branch.Children {
+				branch.PostCount += child.PostCount
+			}
+			tree = append(tree, branch)
+		}
+		return tree
+	}
+	return build(roots)
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCategoryTree(t *testing.T) {
+	parent := func(id uint) *uint { return &id }
+	categories := []Category{
+		{ID: 1, Slug: "tech", PostCount: 1},
+		{ID: 2, Slug: "go", ParentID: parent(1), PostCount: 2},
+		{ID: 3, Slug: "generics", ParentID: parent(2), PostCount: 4},
+		{ID: 4, Slug: "rust", ParentID: parent(1)},
+		{ID: 5, Slug: "life", PostCount: 8},
+	}
+
+	type node struct {
+		Slug     string
+		Count    int64
+		Children []node
+	}
+	var flatten func(tree []CategoryTreeResponse) []node
+	flatten = func(tree []CategoryTreeResponse) []node {
+		nodes := []node{}
+		for _, branch := range tree {
+			nodes = append(nodes, node{branch.Slug, branch.PostCount, flatten(branch.Children)})
+		}
+		return nodes
+	}
+
+	want := []node{
+		{"tech", 7, []node{
+			{"go", 6, []node{{"generics", 4, []node{}}}},
+			{"rust", 0, []node{}},
+		}},
+		{"life", 8, []node{}},
+	}
+	if got := flatten(CategoryTree(categories)); !reflect.DeepEqual(got, want) {
+		t.Errorf("CategoryTree = %+v, want %+v", got, want)
+	}
+
+	if got := CategoryTree(nil); got == nil || len(got) != 0 {
+		t.Errorf("CategoryTree(nil) = %#v, want an empty list", got)
+	}
+}
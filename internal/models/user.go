@@ -1,6 +1,7 @@
 package models
 
 import (
+	"mime/multipart"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,32 +9,50 @@ import (
 
 // User represents the user model
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null"`
-	Password  string         `json:"-" gorm:"not null"`
-	FirstName string         `json:"first_name" gorm:"not null"`
-	LastName  string         `json:"last_name" gorm:"not null"`
-	IsActive  bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	Email              string         `json:"email" gorm:"uniqueIndex;not null"`
+	Password           string         `json:"-" gorm:"not null"`
+	FirstName          string         `json:"first_name" gorm:"not null"`
+	LastName           string         `json:"last_name" gorm:"not null"`
+	AvatarURL          string         `json:"avatar_url" gorm:"size:255"`
+	AvatarID           string         `json:"-" gorm:"size:255"`
+	IsActive           bool           `json:"is_active" gorm:"default:true"`
+	Role               Role           `json:"role" gorm:"size:20;not null;default:author"`
+	EmailVerifiedAt    *time.Time     `json:"email_verified_at"`
+	VerificationSentAt *time.Time     `json:"-"`
+	MFAEnabled         bool           `json:"mfa_enabled" gorm:"default:false"`
+	TOTPSecret         string         `json:"-" gorm:"size:64"`
+	TOTPLastStep       int64          `json:"-" gorm:"default:0"`
+	FailedLogins       int            `json:"-" gorm:"default:0"`
+	LastFailedLoginAt  *time.Time     `json:"-"`
+	LockedUntil        *time.Time     `json:"-"`
+	ResetEmailsSent    int            `json:"-" gorm:"default:0"`
+	ResetWindowStart   *time.Time     `json:"-"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // UserResponse represents the user response without sensitive data
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint       `json:"id"`
+	Email           string     `json:"email"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	AvatarURL       string     `json:"avatar_url"`
+	IsActive        bool       `json:"is_active"`
+	Role            Role       `json:"role"`
+	EmailVerified   bool       `json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	MFAEnabled      bool       `json:"mfa_enabled"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 // CreateUserRequest represents the request to create a new user
 type CreateUserRequest struct {
-	Email     string `json:"email" validate:"required,email"`
-	Password  string `json:"password" validate:"required,min=6"`
+	Email     string `json:"email" validate:"required,email,unique_email"`
+	Password  string `json:"password" validate:"required,strong_password"`
 	FirstName string `json:"first_name" validate:"required"`
 	LastName  string `json:"last_name" validate:"required"`
 }
@@ -50,8 +69,12 @@ type LoginRequest struct {
 
 // LoginResponse represents the login response
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token        string        `json:"token,omitempty"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
+	ExpiresIn    int64         `json:"expires_in,omitempty"`
+	User         *UserResponse `json:"user,omitempty"`
+	MFARequired  bool          `json:"mfa_required,omitempty"`
+	MFAToken     string        `json:"mfa_token,omitempty"`
 }
 
 // ForgotPasswordRequest represents the forgot password request
@@ -59,21 +82,94 @@ type ForgotPasswordRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
 
+// MagicLinkRequest represents the request for a passwordless sign-in link
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// MagicLinkVerifyRequest represents the request to exchange a sign-in link for tokens
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
 // ResetPasswordRequest represents the reset password request
 type ResetPasswordRequest struct {
 	Token       string `json:"token" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=6"`
+	NewPassword string `json:"new_password" validate:"required,strong_password"`
+}
+
+// VerifyEmailRequest represents the verify email request
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ResendVerificationRequest represents the resend verification email request
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// UpdateProfileRequest represents the request to update the caller's profile
+type UpdateProfileRequest struct {
+	FirstName string                `json:"first_name" form:"first_name"`
+	LastName  string                `json:"last_name" form:"last_name"`
+	Avatar    *multipart.FileHeader `json:"-" form:"avatar"`
+}
+
+// ChangePasswordRequest represents the request to change the caller's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,strong_password"`
+}
+
+// What happens to a user's blogs when the account is deleted
+const (
+	BlogActionTransfer  = "transfer"
+	BlogActionAnonymize = "anonymize"
+	BlogActionCascade   = "cascade"
+)
+
+// DeleteAccountRequest represents the request to delete the caller's account
+// Accounts without a password confirm with a recent sign-in instead.
+type DeleteAccountRequest struct {
+	Password        string `json:"password"`
+	BlogAction      string `json:"blog_action" validate:"required,oneof=transfer anonymize cascade"`
+	TransferToEmail string `json:"transfer_to_email" validate:"omitempty,email"`
+}
+
+// UnlockAccountRequest represents the unlock account request
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// IsLocked reports whether the account is temporarily locked after failed logins
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// IsEmailVerified reports whether the user has confirmed their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// HasPermission reports whether the user's role grants the given permission
+func (u *User) HasPermission(permission Permission) bool {
+	return u.Role.HasPermission(permission)
 }
 
 // ToResponse converts User to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Email:     u.Email,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		IsActive:  u.IsActive,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:              u.ID,
+		Email:           u.Email,
+		FirstName:       u.FirstName,
+		LastName:        u.LastName,
+		AvatarURL:       u.AvatarURL,
+		IsActive:        u.IsActive,
+		Role:            u.Role,
+		EmailVerified:   u.IsEmailVerified(),
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		MFAEnabled:      u.MFAEnabled,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
 	}
 }
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/controllers"
+	"go-fiber-boilerplate/internal/middlewares"
+	"go-fiber-boilerplate/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupAdminRoutes(api fiber.Router, cfg *config.Config) {
+	adminController := controllers.NewAdminController(cfg)
+	taxonomyController := controllers.NewTaxonomyController(cfg)
+
+	admin := api.Group("/admin",
+		middlewares.AuthMiddleware(cfg),
+		middlewares.RequirePermission(models.PermissionUserManage),
+	)
+
+	admin.Get("/users", adminController.GetUsers)
+	admin.Get("/users/:id", adminController.GetUser)
+	admin.Patch("/users/:id/status", adminController.UpdateUserStatus)
+	admin.Patch("/users/:id/role", adminController.UpdateUserRole)
+	admin.Post("/users/:id/password-reset", adminController.SendPasswordReset)
+	admin.Get("/users/:id/blogs", adminController.GetUserBlogs)
+	admin.Get("/audit-logs", adminController.GetAuditLogs)
+
+	admin.Patch("/tags/:id", taxonomyController.RenameTag)
+	admin.Post("/tags/:id/merge", taxonomyController.MergeTags)
+	admin.Post("/categories", taxonomyController.CreateCategory)
+	admin.Patch("/categories/:id", taxonomyController.UpdateCategory)
+	admin.Delete("/categories/:id", taxonomyController.DeleteCategory)
+}
@@ -3,17 +3,42 @@ package routes
 import (
 	"go-fiber-boilerplate/config"
 	"go-fiber-boilerplate/internal/controllers"
+	"go-fiber-boilerplate/internal/middlewares"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func SetupAuthRoutes(api fiber.Router, cfg *config.Config) {
 	authController := controllers.NewAuthController(cfg)
+	mfaController := controllers.NewMFAController(cfg)
+	oidcController := controllers.NewOIDCController(cfg)
 
 	auth := api.Group("/auth")
 
 	auth.Post("/register", authController.Register)
 	auth.Post("/login", authController.Login)
+	auth.Post("/refresh", authController.RefreshToken)
+	auth.Post("/logout", middlewares.AuthMiddleware(cfg), middlewares.RequireSessionAuth(), authController.Logout)
+	auth.Post("/logout-all", middlewares.AuthMiddleware(cfg), middlewares.RequireSessionAuth(), authController.LogoutAll)
+	auth.Post("/magic-link", authController.RequestMagicLink)
+	auth.Post("/magic-link/verify", authController.VerifyMagicLink)
 	auth.Post("/forgot-password", authController.ForgotPassword)
 	auth.Post("/reset-password", authController.ResetPassword)
+	auth.Post("/unlock-account", authController.UnlockAccount)
+	auth.Get("/verify-email", authController.VerifyEmail)
+	auth.Post("/verify-email", authController.VerifyEmail)
+	auth.Post("/resend-verification", authController.ResendVerification)
+
+	mfa := auth.Group("/mfa")
+	mfa.Post("/verify", authController.VerifyMFA)
+	mfa.Post("/totp/setup", middlewares.AuthMiddleware(cfg), middlewares.RequireSessionAuth(), mfaController.SetupTOTP)
+	mfa.Post("/totp/confirm", middlewares.AuthMiddleware(cfg), middlewares.RequireSessionAuth(), mfaController.ConfirmTOTP)
+	mfa.Post("/totp/disable", middlewares.AuthMiddleware(cfg), middlewares.RequireSessionAuth(), mfaController.DisableTOTP)
+	mfa.Post("/recovery-codes", middlewares.AuthMiddleware(cfg), middlewares.RequireSessionAuth(), mfaController.RegenerateRecoveryCodes)
+
+	oidc := auth.Group("/oidc")
+	oidc.Get("/providers", oidcController.GetProviders)
+	oidc.Get("/:provider/authorize", oidcController.StartLogin)
+	oidc.Get("/:provider/callback", oidcController.Callback)
+	oidc.Post("/:provider/callback", oidcController.Callback)
 }
@@ -4,6 +4,7 @@ import (
 	"go-fiber-boilerplate/config"
 	"go-fiber-boilerplate/internal/controllers"
 	"go-fiber-boilerplate/internal/middlewares"
+	"go-fiber-boilerplate/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,11 +19,13 @@ func SetupBlogRouter(api fiber.Router, cfg *config.Config) {
 
 	blogs.Post("/",
 		middlewares.AuthMiddleware(cfg),
+		middlewares.RequirePermission(models.PermissionBlogCreate),
 		middlewares.NewUploaderMiddleware().ImageUpload(2, []string{"image/jpeg", "image/png"}),
 		blogController.CreateBlog,
 	)
-	blogs.Get("/", blogController.GetBlogs)
-	blogs.Get("/:id", blogController.GetBlogById)
+	blogs.Get("/", middlewares.OptionalAuthMiddleware(cfg), blogController.GetBlogs)
+	blogs.Get("/slug/:slug", middlewares.OptionalAuthMiddleware(cfg), blogController.GetBlogBySlug).Name(controllers.BlogSlugRoute)
+	blogs.Get("/:id", middlewares.OptionalAuthMiddleware(cfg), blogController.GetBlogById)
 	blogs.Patch("/:id",
 		middlewares.AuthMiddleware(cfg),
 		middlewares.NewUploaderMiddleware().ImageUpload(2, []string{"image/jpeg", "image/png"}),
@@ -32,4 +35,10 @@ func SetupBlogRouter(api fiber.Router, cfg *config.Config) {
 		middlewares.AuthMiddleware(cfg),
 		blogController.DeleteBlog,
 	)
+
+	revisions := blogs.Group("/:id/revisions", middlewares.AuthMiddleware(cfg))
+	revisions.Get("/", blogController.GetRevisions)
+	revisions.Get("/diff", blogController.DiffRevisions)
+	revisions.Get("/:rev", blogController.GetRevision)
+	revisions.Post("/:rev/restore", blogController.RestoreRevision)
 }
@@ -15,7 +15,11 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 
     api := app.Group("/")
 
+    SetupWellKnownRoutes(api, cfg)
     SetupAuthRoutes(api, cfg)
 	SetupSampleRoutes(api, cfg)
     SetupBlogRouter(api, cfg)
+    SetupTaxonomyRoutes(api, cfg)
+    SetupUserRoutes(api, cfg)
+    SetupAdminRoutes(api, cfg)
 }
\ No newline at end of file
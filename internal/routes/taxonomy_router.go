@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupTaxonomyRoutes registers the public tag and category listings; they
+// are managed through the admin routes
+func SetupTaxonomyRoutes(api fiber.Router, cfg *config.Config) {
+	taxonomyController := controllers.NewTaxonomyController(cfg)
+
+	api.Get("/tags", taxonomyController.GetTags)
+	api.Get("/categories", taxonomyController.GetCategories)
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/controllers"
+	"go-fiber-boilerplate/internal/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupUserRoutes(api fiber.Router, cfg *config.Config) {
+	userController, err := controllers.NewUserController(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	blogController, err := controllers.NewBlogController(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	apiKeyController := controllers.NewAPIKeyController(cfg)
+	oidcController := controllers.NewOIDCController(cfg)
+	sessionController := controllers.NewSessionController(cfg)
+
+	users := api.Group("/users", middlewares.AuthMiddleware(cfg))
+
+	// Every change to the account itself needs a session; API keys are meant
+	// for resources such as blogs
+	users.Get("/me", userController.GetMe)
+	users.Patch("/me", middlewares.RequireSessionAuth(), userController.UpdateMe)
+	users.Delete("/me", middlewares.RequireSessionAuth(), userController.DeleteMe)
+	users.Post("/me/password", middlewares.RequireSessionAuth(), userController.ChangePassword)
+	users.Get("/me/blogs", blogController.GetMyBlogs)
+
+	sessions := users.Group("/me/sessions", middlewares.RequireSessionAuth())
+	sessions.Get("/", sessionController.GetSessions)
+	sessions.Delete("/:id", sessionController.RevokeSession)
+
+	// API keys can never be used to mint or manage other API keys
+	apiKeys := users.Group("/me/api-keys", middlewares.RequireSessionAuth())
+	apiKeys.Get("/", apiKeyController.GetAPIKeys)
+	apiKeys.Post("/", apiKeyController.CreateAPIKey)
+	apiKeys.Delete("/:id", apiKeyController.RevokeAPIKey)
+
+	identities := users.Group("/me/identities", middlewares.RequireSessionAuth())
+	identities.Get("/", oidcController.GetIdentities)
+	identities.Post("/:provider", oidcController.StartLink)
+	identities.Post("/:provider/callback", oidcController.CompleteLink)
+	identities.Delete("/:provider", oidcController.Unlink)
+}
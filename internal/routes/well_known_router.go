@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupWellKnownRoutes(api fiber.Router, cfg *config.Config) {
+	jwksController := controllers.NewJWKSController()
+
+	wellKnown := api.Group("/.well-known")
+	wellKnown.Get("/jwks.json", jwksController.GetJWKS)
+}
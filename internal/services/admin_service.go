@@ -0,0 +1,215 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AdminService struct {
+	cfg         *config.Config
+	authService *AuthService
+}
+
+func NewAdminService(cfg *config.Config) *AdminService {
+	return &AdminService{
+		cfg:         cfg,
+		authService: NewAuthService(cfg),
+	}
+}
+
+func (s *AdminService) GetUsers(params models.AdminUserQueryParams) ([]models.User, int64, error) {
+	query := database.GetDB().Model(&models.User{})
+
+	if search := strings.TrimSpace(params.Search); search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ?", pattern, pattern, pattern)
+	}
+	if params.Role != "" {
+		query = query.Where("role = ?", params.Role)
+	}
+	if params.IsActive != nil {
+		query = query.Where("is_active = ?", *params.IsActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, ErrDatabase.Wrap(err)
+	}
+
+	var users []models.User
+	offset := (params.Page - 1) * params.Limit
+	if err := query.
+		Order("id ASC").
+		Offset(offset).
+		Limit(params.Limit).
+		Find(&users).Error; err != nil {
+		return nil, 0, ErrDatabase.Wrap(err)
+	}
+
+	return users, total, nil
+}
+
+func (s *AdminService) GetUser(id uint) (*models.User, error) {
+	var user models.User
+	if err := database.GetDB().First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return &user, nil
+}
+
+func (s *AdminService) SetUserActive(actor Actor, meta ClientMeta, id uint, isActive bool) (*models.User, error) {
+	if actor.UserID == id && !isActive {
+		return nil, ErrDeactivateSelf
+	}
+
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	action := AuditUserActivated
+	if !isActive {
+		action = AuditUserDeactivated
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("is_active", isActive).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, meta, action, "user", user.ID, nil)
+	})
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	// A deactivated user must be kicked out right away, not when their token expires
+	if !isActive {
+		if err := s.authService.revokeAllSessions(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+func (s *AdminService) ChangeUserRole(actor Actor, meta ClientMeta, id uint, role models.Role) (*models.User, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	if actor.UserID == id {
+		return nil, ErrChangeOwnRole
+	}
+
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousRole := user.Role
+	if previousRole == role {
+		return user, nil
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("role", role).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, meta, AuditUserRoleChanged, "user", user.ID, map[string]interface{}{
+			"from": previousRole,
+			"to":   role,
+		})
+	})
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	// Access tokens embed the role, so the old ones are revoked. Refresh
+	// tokens stay valid and pick up the new role on their next rotation.
+	if err := GetRevocationStore().RevokeAllForUser(user.ID, s.cfg.AccessTokenTTL); err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	return user, nil
+}
+
+func (s *AdminService) SendPasswordReset(actor Actor, meta ClientMeta, id uint) error {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authService.sendPasswordResetEmail(user); err != nil {
+		return err
+	}
+
+	if err := recordAudit(database.GetDB(), actor, meta, AuditUserPasswordResetSent, "user", user.ID, nil); err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+	return nil
+}
+
+func (s *AdminService) GetUserBlogs(id uint, page, limit int) ([]models.Blog, int64, error) {
+	if _, err := s.GetUser(id); err != nil {
+		return nil, 0, err
+	}
+
+	var blogs []models.Blog
+	offset := (page - 1) * limit
+
+	if err := withBlogRelations(database.GetDB()).
+		Where("user_id = ?", id).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&blogs).Error; err != nil {
+		return nil, 0, ErrDatabase.Wrap(err)
+	}
+
+	var total int64
+	if err := database.GetDB().Model(&models.Blog{}).Where("user_id = ?", id).Count(&total).Error; err != nil {
+		return nil, 0, ErrDatabase.Wrap(err)
+	}
+
+	return blogs, total, nil
+}
+
+func (s *AdminService) GetAuditLogs(params models.AuditLogQueryParams) ([]models.AuditLog, int64, error) {
+	query := database.GetDB().Model(&models.AuditLog{})
+
+	if params.ActorID != 0 {
+		query = query.Where("actor_id = ?", params.ActorID)
+	}
+	if params.TargetID != 0 {
+		query = query.Where("target_id = ?", params.TargetID)
+	}
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, ErrDatabase.Wrap(err)
+	}
+
+	var logs []models.AuditLog
+	offset := (params.Page - 1) * params.Limit
+	if err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(params.Limit).
+		Find(&logs).Error; err != nil {
+		return nil, 0, ErrDatabase.Wrap(err)
+	}
+
+	return logs, total, nil
+}
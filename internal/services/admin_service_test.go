@@ -0,0 +1,32 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+)
+
+func TestGetUserBlogs(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "listed@example.com")
+	createTestBlog(t, user, models.CreateBlogRequest{Title: "Draft"})
+	createTestBlog(t, user, models.CreateBlogRequest{Title: "Live", Published: true})
+	service := NewAdminService(&config.Config{})
+
+	blogs, total, err := service.GetUserBlogs(user.ID, 1, 10)
+	if err != nil {
+		t.Fatalf("GetUserBlogs: %v", err)
+	}
+	if total != 2 || len(blogs) != 2 {
+		t.Errorf("GetUserBlogs = %d blogs of %d, want 2 of 2", len(blogs), total)
+	}
+
+	// An unknown user is a 404, not a database failure
+	_, _, err = service.GetUserBlogs(user.ID+1, 1, 10)
+	var serviceErr *Error
+	if !errors.As(err, &serviceErr) || serviceErr.Code != ErrUserNotFound.Code {
+		t.Errorf("unknown user: GetUserBlogs = %v, want %v", err, ErrUserNotFound)
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyPrefix            = "bk"
+	defaultAPIKeyExpiryDays = 90
+	maxAPIKeyExpiryDays     = 365
+	apiKeyLastUsedInterval  = time.Minute
+)
+
+type APIKeyService struct {
+	cfg *config.Config
+}
+
+func NewAPIKeyService(cfg *config.Config) *APIKeyService {
+	return &APIKeyService{cfg: cfg}
+}
+
+// CreateAPIKey issues a key of the form "bk_<prefix>_<secret>". The full key
+// is returned once and cannot be recovered afterwards.
+func (s *APIKeyService) CreateAPIKey(userID uint, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrAPIKeyNameRequired
+	}
+	if len(name) > 100 {
+		return nil, ErrAPIKeyNameTooLong
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, ErrAPIKeyScopeRequired
+	}
+
+	expiresInDays := req.ExpiresInDays
+	if expiresInDays == 0 {
+		expiresInDays = defaultAPIKeyExpiryDays
+	}
+	if expiresInDays < 1 || expiresInDays > maxAPIKeyExpiryDays {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+
+	var user models.User
+	if err := database.GetDB().First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	// A key can never be granted more than its owner is allowed to do
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !user.HasPermission(scope) {
+			return nil, ErrInvalidAPIKeyScope
+		}
+		scopes = append(scopes, string(scope))
+	}
+
+	prefix, err := utils.GenerateRandomToken(4)
+	if err != nil {
+		return nil, ErrInternal.Wrap(err)
+	}
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, ErrInternal.Wrap(err)
+	}
+
+	apiKey := models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(secret),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: time.Now().AddDate(0, 0, expiresInDays),
+	}
+
+	if err := database.GetDB().Create(&apiKey).Error; err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	return &models.CreateAPIKeyResponse{
+		APIKeyResponse: apiKey.ToResponse(),
+		Key:            apiKeyPrefix + "_" + prefix + "_" + secret,
+	}, nil
+}
+
+func (s *APIKeyService) GetAPIKeys(userID uint) ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+	if err := database.GetDB().
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&apiKeys).Error; err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return apiKeys, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(userID, id uint) error {
+	var apiKey models.APIKey
+	if err := database.GetDB().Where("id = ? AND user_id = ?", id, userID).First(&apiKey).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return ErrDatabase.Wrap(err)
+	}
+
+	if apiKey.RevokedAt != nil {
+		return nil
+	}
+
+	if err := database.GetDB().Model(&apiKey).Update("revoked_at", time.Now()).Error; err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+	return nil
+}
+
+// Authenticate resolves a raw API key to its owner
+func (s *APIKeyService) Authenticate(rawKey string) (*models.User, *models.APIKey, error) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	var apiKey models.APIKey
+	if err := database.GetDB().Where("prefix = ?", parts[1]).First(&apiKey).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		return nil, nil, ErrDatabase.Wrap(err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(parts[2])), []byte(apiKey.KeyHash)) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || now.After(apiKey.ExpiresAt) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	var user models.User
+	if err := database.GetDB().First(&user, apiKey.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		return nil, nil, ErrDatabase.Wrap(err)
+	}
+
+	if !user.IsActive {
+		return nil, nil, ErrAccountDeactivated
+	}
+
+	// Writing on every request would turn each API call into a database write
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyLastUsedInterval {
+		database.GetDB().Model(&apiKey).Update("last_used_at", now)
+	}
+
+	return &user, &apiKey, nil
+}
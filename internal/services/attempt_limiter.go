@@ -0,0 +1,141 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"go-fiber-boilerplate/config"
+)
+
+// AttemptLimiter tracks failed attempts per key (an IP address or an email)
+// in memory and blocks the key with exponential backoff once the threshold
+// is reached. Keys are forgotten after a quiet window without failures.
+type AttemptLimiter struct {
+	mu        sync.Mutex
+	attempts  map[string]*attemptRecord
+	threshold int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	window    time.Duration
+	now       func() time.Time
+}
+
+type attemptRecord struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+func NewAttemptLimiter(threshold int, baseDelay, maxDelay, window time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		attempts:  make(map[string]*attemptRecord),
+		threshold: threshold,
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+		window:    window,
+		now:       time.Now,
+	}
+}
+
+// Blocked reports whether the key is currently blocked and for how long
+func (l *AttemptLimiter) Blocked(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record, exists := l.attempts[key]
+	if !exists {
+		return 0, false
+	}
+
+	now := l.now()
+	if now.Before(record.blockedUntil) {
+		return record.blockedUntil.Sub(now), true
+	}
+	return 0, false
+}
+
+// Fail records a failed attempt and returns how long the key is now blocked for
+func (l *AttemptLimiter) Fail(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	record, exists := l.attempts[key]
+	if !exists {
+		record = &attemptRecord{}
+		l.attempts[key] = record
+	}
+
+	record.failures++
+	record.lastFailure = now
+
+	delay := BackoffDelay(record.failures, l.threshold, l.baseDelay, l.maxDelay)
+	if delay > 0 {
+		record.blockedUntil = now.Add(delay)
+	}
+	return delay
+}
+
+// Reset forgets every failure recorded for the key
+func (l *AttemptLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, key)
+}
+
+func (l *AttemptLimiter) cleanup(now time.Time) {
+	for key, record := range l.attempts {
+		if now.Sub(record.lastFailure) > l.window && !now.Before(record.blockedUntil) {
+			delete(l.attempts, key)
+		}
+	}
+}
+
+// BackoffDelay returns 0 below the threshold, then baseDelay doubling with
+// every further failure, capped at maxDelay
+func BackoffDelay(failures, threshold int, baseDelay, maxDelay time.Duration) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+
+	delay := baseDelay
+	for i := threshold; i < failures; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
+// authLimiters groups the process-wide limiters guarding the auth endpoints
+type authLimiters struct {
+	loginIP          *AttemptLimiter
+	loginEmail       *AttemptLimiter
+	forgotPasswordIP *AttemptLimiter
+	magicLinkIP      *AttemptLimiter
+}
+
+var (
+	sharedAuthLimiters *authLimiters
+	authLimitersOnce   sync.Once
+)
+
+func getAuthLimiters(cfg *config.Config) *authLimiters {
+	authLimitersOnce.Do(func() {
+		sharedAuthLimiters = &authLimiters{
+			loginIP:          NewAttemptLimiter(cfg.MaxLoginAttemptsPerIP, cfg.LoginLockoutBase, cfg.LoginLockoutMax, cfg.LoginAttemptWindow),
+			loginEmail:       NewAttemptLimiter(cfg.MaxLoginAttempts, cfg.LoginLockoutBase, cfg.LoginLockoutMax, cfg.LoginAttemptWindow),
+			forgotPasswordIP: NewAttemptLimiter(cfg.ForgotPasswordIPLimit, time.Hour, time.Hour, time.Hour),
+			magicLinkIP:      NewAttemptLimiter(cfg.ForgotPasswordIPLimit, time.Hour, time.Hour, time.Hour),
+		}
+	})
+	return sharedAuthLimiters
+}
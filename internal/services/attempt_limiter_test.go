@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{7, 4 * time.Minute},
+		{9, 16 * time.Minute},
+		{10, 30 * time.Minute},
+		{1000, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := BackoffDelay(tt.failures, 5, time.Minute, 30*time.Minute); got != tt.want {
+			t.Errorf("BackoffDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+
+	if got := BackoffDelay(1, 1, time.Hour, time.Minute); got != time.Minute {
+		t.Errorf("base above the cap: BackoffDelay = %v, want %v", got, time.Minute)
+	}
+}
+
+func TestAttemptLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(3, time.Minute, 4*time.Minute, time.Hour)
+	limiter.now = func() time.Time { return now }
+
+	blocked := func(key string, want time.Duration) {
+		t.Helper()
+		delay, isBlocked := limiter.Blocked(key)
+		if delay != want || isBlocked != (want > 0) {
+			t.Errorf("Blocked(%s) = %v, %v; want %v", key, delay, isBlocked, want)
+		}
+	}
+
+	// Below the threshold failures are only counted
+	for i := 0; i < 2; i++ {
+		if delay := limiter.Fail("a"); delay != 0 {
+			t.Fatalf("failure %d blocked for %v", i+1, delay)
+		}
+	}
+	blocked("a", 0)
+
+	// Then every failure doubles the block, up to the cap
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		if delay := limiter.Fail("a"); delay != want {
+			t.Errorf("Fail = %v, want %v", delay, want)
+		}
+	}
+	blocked("a", 4*time.Minute)
+	blocked("b", 0)
+
+	now = now.Add(time.Minute)
+	blocked("a", 3*time.Minute)
+	now = now.Add(3 * time.Minute)
+	blocked("a", 0)
+
+	// The failures are remembered until a quiet window has passed
+	if delay := limiter.Fail("a"); delay != 4*time.Minute {
+		t.Errorf("failure after the block = %v, want %v", delay, 4*time.Minute)
+	}
+	now = now.Add(4*time.Minute + time.Hour + time.Second)
+	limiter.Fail("b")
+	if _, exists := limiter.attempts["a"]; exists {
+		t.Error("key a survived a quiet window")
+	}
+	if delay := limiter.Fail("a"); delay != 0 {
+		t.Errorf("first failure after the window = %v, want 0", delay)
+	}
+
+	// A success wipes the slate
+	limiter.Fail("a")
+	limiter.Fail("a")
+	limiter.Reset("a")
+	if delay := limiter.Fail("a"); delay != 0 {
+		t.Errorf("first failure after Reset = %v, want 0", delay)
+	}
+}
+
+func TestAttemptLimiterKeepsBlockedKeys(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(1, 2*time.Hour, 2*time.Hour, time.Hour)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Fail("a")
+	now = now.Add(90 * time.Minute)
+	limiter.Fail("b")
+
+	// The window has passed, but the block has not run out yet
+	if _, isBlocked := limiter.Blocked("a"); !isBlocked {
+		t.Error("cleanup dropped a key that is still blocked")
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"encoding/json"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Audit actions
+const (
+	AuditUserActivated         = "user.activated"
+	AuditUserDeactivated       = "user.deactivated"
+	AuditUserRoleChanged       = "user.role_changed"
+	AuditUserPasswordResetSent = "user.password_reset_sent"
+	AuditTagRenamed            = "tag.renamed"
+	AuditTagMerged             = "tag.merged"
+	AuditCategoryCreated       = "category.created"
+	AuditCategoryUpdated       = "category.updated"
+	AuditCategoryDeleted       = "category.deleted"
+)
+
+// recordAudit appends an entry to the audit trail, inside tx when the action is transactional
+func recordAudit(tx *gorm.DB, actor Actor, meta ClientMeta, action, targetType string, targetID uint, details map[string]interface{}) error {
+	encoded := ""
+	if len(details) > 0 {
+		bytes, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		encoded = string(bytes)
+	}
+
+	return tx.Create(&models.AuditLog{
+		ActorID:    actor.UserID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    encoded,
+		IP:         meta.IP,
+	}).Error
+}
@@ -3,27 +3,41 @@ package services
 import (
 	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
+
 	"go-fiber-boilerplate/config"
 	"go-fiber-boilerplate/database"
 	"go-fiber-boilerplate/internal/models"
 	"go-fiber-boilerplate/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AuthService struct {
-	cfg *config.Config
+	cfg        *config.Config
+	mfaService *MFAService
 }
 
 func NewAuthService(cfg *config.Config) *AuthService {
-	return &AuthService{cfg: cfg}
+	return &AuthService{
+		cfg:        cfg,
+		mfaService: NewMFAService(cfg),
+	}
 }
 
 func (s *AuthService) Register(req models.CreateUserRequest) (*models.RegisterResponse, error) {
 
 	var existingUser models.User
 	if err := database.GetDB().Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
-		return nil, errors.New("user with this email already exists")
+		return nil, ErrEmailTaken
+	}
+
+	candidate := models.User{Email: req.Email, FirstName: req.FirstName, LastName: req.LastName}
+	if err := getPasswordPolicy(s.cfg).Validate("password", req.Password, &candidate); err != nil {
+		return nil, err
 	}
 
 	hashedPassword, err := utils.HashPassword(req.Password)
@@ -37,55 +51,218 @@ func (s *AuthService) Register(req models.CreateUserRequest) (*models.RegisterRe
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		IsActive:  true,
+		Role:      models.RoleAuthor,
 	}
 
 	if err := database.GetDB().Create(&user).Error; err != nil {
 		return nil, err
 	}
 
+	if err := s.sendVerificationEmail(&user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+
 	return &models.RegisterResponse{
 		User: user.ToResponse(),
 	}, nil
 }
 
-func (s *AuthService) Login(req models.LoginRequest) (*models.LoginResponse, error) {
+func (s *AuthService) Login(req models.LoginRequest, meta ClientMeta) (*models.LoginResponse, error) {
+
+	limiters := getAuthLimiters(s.cfg)
+	ipKey := "ip:" + meta.IP
+	emailKey := "email:" + strings.ToLower(req.Email)
+
+	// Unknown emails are throttled exactly like real accounts so the
+	// lockout cannot be used to discover which addresses are registered
+	if _, blocked := limiters.loginIP.Blocked(ipKey); blocked {
+		return nil, ErrTooManyLogins
+	}
+	if _, blocked := limiters.loginEmail.Blocked(emailKey); blocked {
+		return nil, ErrTooManyLogins
+	}
 
 	var user models.User
 	if err := database.GetDB().Where("email = ?", req.Email).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("invalid credentials")
+			limiters.loginIP.Fail(ipKey)
+			limiters.loginEmail.Fail(emailKey)
+			return nil, ErrInvalidCredentials
 		}
-		return nil, errors.New("database error")
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	if user.IsLocked(time.Now()) {
+		return nil, ErrTooManyLogins
 	}
 
 	if !utils.CheckPassword(req.Password, user.Password) {
-		return nil, errors.New("invalid credentials")
+		limiters.loginIP.Fail(ipKey)
+		limiters.loginEmail.Fail(emailKey)
+		s.registerFailedLogin(&user)
+		return nil, ErrInvalidCredentials
+	}
+
+	limiters.loginEmail.Reset(emailKey)
+	s.clearFailedLogins(&user)
+	s.upgradePasswordHash(&user, req.Password)
+
+	return s.completeLogin(&user, meta)
+}
+
+func (s *AuthService) VerifyMFA(req models.MFAVerifyRequest, meta ClientMeta) (*models.LoginResponse, error) {
+
+	if req.MFAToken == "" || req.Code == "" {
+		return nil, ErrMFACodeRequired
+	}
+
+	claims, err := utils.ValidatePurposeToken(req.MFAToken, utils.TokenTypeMFAPending, s.cfg.JWTSecret)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	var user models.User
+	if err := database.GetDB().Where("id = ? AND email = ?", claims.UserID, claims.Email).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, ErrDatabase.Wrap(err)
 	}
 
 	if !user.IsActive {
-		return nil, errors.New("account is deactivated")
+		return nil, ErrAccountDeactivated
+	}
+
+	if !user.MFAEnabled {
+		return nil, ErrInvalidMFAToken
+	}
+
+	if user.IsLocked(time.Now()) {
+		return nil, ErrTooManyLogins
+	}
+
+	if err := s.mfaService.VerifyCode(&user, req.Code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			s.registerFailedLogin(&user)
+		}
+		return nil, err
 	}
 
-	token, err := utils.GenerateJWT(user.ID, user.Email, s.cfg.JWTSecret)
+	s.clearFailedLogins(&user)
+
+	return s.issueTokens(&user, meta)
+}
+
+func (s *AuthService) RefreshToken(refreshToken string, meta ClientMeta) (*models.LoginResponse, error) {
+
+	if refreshToken == "" {
+		return nil, ErrRefreshRequired
+	}
+
+	var stored models.RefreshToken
+	if err := database.GetDB().Where("token_hash = ?", utils.HashToken(refreshToken)).First(&stored).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	// A token that was already rotated or revoked is being replayed, so the
+	// family has to be treated as compromised
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		s.revokeRefreshFamily(stored.FamilyID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var user models.User
+	if err := database.GetDB().First(&user, stored.UserID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			s.revokeRefreshFamily(stored.FamilyID)
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	if !user.IsActive {
+		s.revokeRefreshFamily(stored.FamilyID)
+		return nil, ErrAccountDeactivated
+	}
+
+	// Login would turn these away too; the session is kept for when they pass
+	if user.IsLocked(time.Now()) {
+		return nil, ErrTooManyLogins
+	}
+	if s.cfg.EmailVerificationEnforcement == "login" && !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	var newRefreshToken string
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		// Guard against two concurrent requests rotating the same token
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", stored.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		token, err := s.createRefreshToken(tx, user.ID, stored.FamilyID)
+		if err != nil {
+			return err
+		}
+		newRefreshToken = token
+
+		return refreshSession(tx, user.ID, stored.FamilyID, meta, time.Now().Add(s.cfg.RefreshTokenTTL))
+	})
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			s.revokeRefreshFamily(stored.FamilyID)
+			return nil, err
+		}
+		if errors.Is(err, ErrSessionRevoked) {
+			s.revokeRefreshFamily(stored.FamilyID)
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	accessToken, err := utils.GenerateJWT(user.ID, user.Email, string(user.Role), stored.FamilyID, JWTConfig(s.cfg), s.cfg.AccessTokenTTL)
 	if err != nil {
 		return nil, err
 	}
 
+	userResponse := user.ToResponse()
 	return &models.LoginResponse{
-		Token: token,
-		User:  user.ToResponse(),
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(s.cfg.AccessTokenTTL.Seconds()),
+		User:         &userResponse,
 	}, nil
 }
 
-func (s *AuthService) ForgotPassword(email string) error {
+func (s *AuthService) ForgotPassword(email string, meta ClientMeta) error {
 
 	if email == "" {
-		return errors.New("email is required")
+		return ErrEmailRequired
 	}
 
 	if !utils.ValidateEmail(email) {
-		return errors.New("invalid email format")
+		return ErrInvalidEmail
+	}
+
+	limiter := getAuthLimiters(s.cfg).forgotPasswordIP
+	ipKey := "ip:" + meta.IP
+	if _, blocked := limiter.Blocked(ipKey); blocked {
+		return ErrTooManyRequests
 	}
+	limiter.Fail(ipKey)
 
 	var user models.User
 	if err := database.GetDB().Where("email = ?", email).First(&user).Error; err != nil {
@@ -93,32 +270,182 @@ func (s *AuthService) ForgotPassword(email string) error {
 
 			return nil
 		}
-		return errors.New("database error")
+		return ErrDatabase.Wrap(err)
 	}
 
-	resetToken, err := utils.GenerateResetPasswordToken(fmt.Sprintf("%d", user.ID), user.Email, s.cfg.JWTSecret)
+	// Over the hourly cap the request is dropped silently, exactly like an unknown email
+	allowed, err := s.reserveResetEmail(&user)
 	if err != nil {
-		return errors.New("failed to generate reset token")
+		return ErrDatabase.Wrap(err)
+	}
+	if !allowed {
+		return nil
 	}
 
-	resetLink := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.FrontendURL, resetToken)
+	// A delivery failure is only logged, otherwise it would reveal that the account exists
+	if err := s.sendPasswordResetEmail(&user); err != nil {
+		log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+	}
 
-	emailConfig := utils.EmailConfig{
-		SMTPHost:     s.cfg.SMTPHost,
-		SMTPPort:     s.cfg.SMTPPort,
-		SMTPUsername: s.cfg.SMTPUsername,
-		SMTPPassword: s.cfg.SMTPPassword,
-		FromEmail:    s.cfg.FromEmail,
+	return nil
+}
+
+// RequestMagicLink emails a single-use sign-in link. Like ForgotPassword it
+// reports success for unknown emails so it cannot be used to probe accounts.
+func (s *AuthService) RequestMagicLink(email string, meta ClientMeta) error {
+
+	if email == "" {
+		return ErrEmailRequired
 	}
 
-	emailData := utils.EmailData{
-		To:      user.Email,
-		Subject: "Reset Your Password",
-		Body:    utils.GenerateResetPasswordEmail(resetLink),
+	if !utils.ValidateEmail(email) {
+		return ErrInvalidEmail
+	}
+
+	limiter := getAuthLimiters(s.cfg).magicLinkIP
+	ipKey := "ip:" + meta.IP
+	if _, blocked := limiter.Blocked(ipKey); blocked {
+		return ErrTooManyRequests
+	}
+	limiter.Fail(ipKey)
+
+	var user models.User
+	if err := database.GetDB().Where("email = ?", email).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return ErrDatabase.Wrap(err)
+	}
+
+	if !user.IsActive {
+		return nil
+	}
+
+	// Sign-in links share the hourly per-address cap with reset emails
+	allowed, err := s.reserveResetEmail(&user)
+	if err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+	if !allowed {
+		return nil
+	}
+
+	// A delivery failure is only logged, otherwise it would reveal that the account exists
+	if err := s.sendMagicLinkEmail(&user); err != nil {
+		log.Printf("Failed to send magic link to user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// VerifyMagicLink exchanges a sign-in link for the normal login response
+func (s *AuthService) VerifyMagicLink(token string, meta ClientMeta) (*models.LoginResponse, error) {
+
+	if token == "" {
+		return nil, ErrTokenRequired
+	}
+
+	var user models.User
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		stored, err := consumeOneTimeToken(tx, token, models.TokenPurposeMagicLink)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.First(&user, stored.UserID).Error; err != nil {
+			return err
+		}
+
+		// The link is bound to the address it was sent to
+		if user.Email != stored.Email {
+			return errInvalidOneTimeToken
+		}
+
+		// Turning the account away rolls back, so the link is not used up
+		if !user.IsActive {
+			return ErrAccountDeactivated
+		}
+		if user.IsLocked(time.Now()) {
+			return ErrTooManyLogins
+		}
+
+		// Opening the link proves the user controls the address
+		if user.EmailVerifiedAt == nil {
+			now := time.Now()
+			return tx.Model(&user).Update("email_verified_at", now).Error
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errInvalidOneTimeToken) || errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMagicLink
+		}
+		return nil, databaseError(err)
+	}
+
+	return s.completeLogin(&user, meta)
+}
+
+func (s *AuthService) VerifyEmail(token string) error {
+
+	if token == "" {
+		return ErrTokenRequired
+	}
+
+	claims, err := utils.ValidateEmailVerificationToken(token, s.cfg.JWTSecret)
+	if err != nil {
+		return ErrInvalidVerifyToken
+	}
+
+	// Matching on email as well makes the link useless once the address changes
+	var user models.User
+	if err := database.GetDB().Where("id = ? AND email = ?", claims.UserID, claims.Email).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrInvalidVerifyToken
+		}
+		return ErrDatabase.Wrap(err)
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	if err := database.GetDB().Model(&user).Update("email_verified_at", time.Now()).Error; err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+
+	return nil
+}
+
+func (s *AuthService) ResendVerification(email string) error {
+
+	if email == "" {
+		return ErrEmailRequired
 	}
 
-	if err := utils.SendEmail(emailConfig, emailData); err != nil {
-		return errors.New("failed to send reset email")
+	if !utils.ValidateEmail(email) {
+		return ErrInvalidEmail
+	}
+
+	var user models.User
+	if err := database.GetDB().Where("email = ?", email).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return ErrDatabase.Wrap(err)
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	// Throttled requests are dropped silently so the response does not reveal the account
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < s.cfg.VerificationResendInterval {
+		return nil
+	}
+
+	if err := s.sendVerificationEmail(&user); err != nil {
+		return ErrVerifyEmailFailed.Wrap(err)
 	}
 
 	return nil
@@ -127,50 +454,441 @@ func (s *AuthService) ForgotPassword(email string) error {
 func (s *AuthService) ResetPassword(token, newPassword string) error {
 
 	if token == "" || newPassword == "" {
-		return errors.New("token and new password are required")
+		return ErrResetFieldsRequired
+	}
+
+	var user models.User
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		resetToken, err := consumeOneTimeToken(tx, token, models.TokenPurposeResetPassword)
+		if err != nil {
+			if errors.Is(err, errInvalidOneTimeToken) {
+				return ErrInvalidResetToken
+			}
+			return ErrDatabase.Wrap(err)
+		}
+
+		// The link is only good for the address it was sent to, and a stale
+		// one says no more about the account than any other invalid link
+		if err := tx.Where("id = ? AND email = ?", resetToken.UserID, resetToken.Email).First(&user).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrInvalidResetToken
+			}
+			return ErrDatabase.Wrap(err)
+		}
+
+		// Failing the policy rolls back, so the link can be used again
+		if err := getPasswordPolicy(s.cfg).Validate("new_password", newPassword, &user); err != nil {
+			return err
+		}
+
+		hashedPassword, err := utils.HashPassword(newPassword)
+		if err != nil {
+			return ErrInternal.Wrap(err)
+		}
+
+		if err := tx.Model(&user).Update("password", hashedPassword).Error; err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+
+		if err := invalidateOneTimeTokens(tx, user.ID, models.TokenPurposeResetPassword); err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	if len(newPassword) < 6 {
-		return errors.New("password must be at least 6 characters long")
+	s.clearFailedLogins(&user)
+
+	// Whoever knew the old password may still hold a session
+	if err := s.revokeAllSessions(user.ID); err != nil {
+		log.Printf("Failed to revoke sessions for user %d after password reset: %v", user.ID, err)
 	}
 
-	claims, err := utils.ValidateResetPasswordToken(token, s.cfg.JWTSecret)
+	emailData := utils.EmailData{
+		To:      user.Email,
+		Subject: "Password Reset Successful",
+		Body:    utils.GeneratePasswordResetSuccessEmail(),
+	}
+
+	utils.SendEmail(s.emailConfig(), emailData)
+
+	return nil
+}
+
+func (s *AuthService) UnlockAccount(token string) error {
+
+	if token == "" {
+		return ErrTokenRequired
+	}
+
+	claims, err := utils.ValidatePurposeToken(token, utils.TokenTypeUnlockAccount, s.cfg.JWTSecret)
 	if err != nil {
-		return errors.New("invalid or expired reset token")
+		return ErrInvalidUnlockToken
 	}
 
 	var user models.User
 	if err := database.GetDB().Where("id = ? AND email = ?", claims.UserID, claims.Email).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return errors.New("user not found")
+			return ErrInvalidUnlockToken
 		}
-		return errors.New("database error")
+		return ErrDatabase.Wrap(err)
+	}
+
+	s.clearFailedLogins(&user)
+	getAuthLimiters(s.cfg).loginEmail.Reset("email:" + strings.ToLower(user.Email))
+
+	return nil
+}
+
+func (s *AuthService) Logout(userID uint, tokenID, sessionID string, tokenExpiresAt time.Time, refreshToken string) error {
+
+	if err := GetRevocationStore().RevokeToken(tokenID, userID, tokenExpiresAt); err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+
+	if err := revokeSessionByID(database.GetDB(), sessionID); err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+
+	if refreshToken != "" {
+		var stored models.RefreshToken
+		err := database.GetDB().
+			Where("token_hash = ? AND user_id = ?", utils.HashToken(refreshToken), userID).
+			First(&stored).Error
+		if err == nil {
+			s.revokeRefreshFamily(stored.FamilyID)
+		} else if err != gorm.ErrRecordNotFound {
+			return ErrDatabase.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+func (s *AuthService) LogoutAll(userID uint) error {
+	return s.revokeAllSessions(userID)
+}
+
+// revokeAllSessions kills every access token and refresh token family of the user
+func (s *AuthService) revokeAllSessions(userID uint) error {
+	if err := GetRevocationStore().RevokeAllForUser(userID, s.cfg.AccessTokenTTL); err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+
+	if err := database.GetDB().Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+
+	if err := database.GetDB().Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return ErrDatabase.Wrap(err)
 	}
 
-	hashedPassword, err := utils.HashPassword(newPassword)
+	return nil
+}
+
+// upgradePasswordHash rehashes the password with the current algorithm and
+// parameters after it was verified. Only possible while the plaintext is at hand.
+func (s *AuthService) upgradePasswordHash(user *models.User, password string) {
+	if !utils.PasswordNeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		log.Printf("Failed to rehash password for user %d: %v", user.ID, err)
+		return
+	}
+
+	// Skip the update if the password was changed concurrently
+	if err := database.GetDB().Model(&models.User{}).
+		Where("id = ? AND password = ?", user.ID, user.Password).
+		Update("password", hashedPassword).Error; err != nil {
+		log.Printf("Failed to store rehashed password for user %d: %v", user.ID, err)
+		return
+	}
+	user.Password = hashedPassword
+}
+
+// registerFailedLogin counts a failed attempt against the account and locks it
+// with exponential backoff once MaxLoginAttempts is reached
+func (s *AuthService) registerFailedLogin(user *models.User) {
+	now := time.Now()
+	justLocked := false
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var current models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, user.ID).Error; err != nil {
+			return err
+		}
+
+		failures := current.FailedLogins + 1
+		if current.LastFailedLoginAt != nil && now.Sub(*current.LastFailedLoginAt) > s.cfg.LoginAttemptWindow {
+			failures = 1
+		}
+
+		updates := map[string]interface{}{
+			"failed_logins":        failures,
+			"last_failed_login_at": now,
+		}
+
+		if delay := BackoffDelay(failures, s.cfg.MaxLoginAttempts, s.cfg.LoginLockoutBase, s.cfg.LoginLockoutMax); delay > 0 {
+			updates["locked_until"] = now.Add(delay)
+			justLocked = failures == s.cfg.MaxLoginAttempts
+		}
+
+		return tx.Model(&current).Updates(updates).Error
+	})
 	if err != nil {
-		return errors.New("failed to hash password")
+		log.Printf("Failed to record failed login for user %d: %v", user.ID, err)
+		return
+	}
+
+	if justLocked {
+		if err := s.sendUnlockEmail(user); err != nil {
+			log.Printf("Failed to send unlock email to %s: %v", user.Email, err)
+		}
+	}
+}
+
+func (s *AuthService) clearFailedLogins(user *models.User) {
+	if user.FailedLogins == 0 && user.LockedUntil == nil {
+		return
 	}
 
-	if err := database.GetDB().Model(&user).Update("password", hashedPassword).Error; err != nil {
-		return errors.New("failed to update password")
+	database.GetDB().Model(user).Updates(map[string]interface{}{
+		"failed_logins":        0,
+		"last_failed_login_at": nil,
+		"locked_until":         nil,
+	})
+}
+
+// reserveResetEmail reports whether another reset email may be sent to the
+// user within the current one hour window and counts it if so
+func (s *AuthService) reserveResetEmail(user *models.User) (bool, error) {
+	allowed := false
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var current models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, user.ID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		windowStart := current.ResetWindowStart
+		sent := current.ResetEmailsSent
+		if windowStart == nil || now.Sub(*windowStart) > time.Hour {
+			windowStart = &now
+			sent = 0
+		}
+
+		if sent >= s.cfg.MaxResetEmailsPerHour {
+			return nil
+		}
+
+		allowed = true
+		return tx.Model(&current).Updates(map[string]interface{}{
+			"reset_emails_sent":  sent + 1,
+			"reset_window_start": windowStart,
+		}).Error
+	})
+
+	return allowed, err
+}
+
+func (s *AuthService) sendUnlockEmail(user *models.User) error {
+	unlockToken, err := utils.GeneratePurposeToken(fmt.Sprintf("%d", user.ID), user.Email, utils.TokenTypeUnlockAccount, s.cfg.JWTSecret, 1*time.Hour)
+	if err != nil {
+		return err
 	}
 
-	emailConfig := utils.EmailConfig{
+	unlockLink := fmt.Sprintf("%s/unlock-account?token=%s", s.cfg.FrontendURL, unlockToken)
+
+	emailData := utils.EmailData{
+		To:      user.Email,
+		Subject: "Your Account Has Been Locked",
+		Body:    utils.GenerateUnlockAccountEmail(unlockLink),
+	}
+
+	return utils.SendEmail(s.emailConfig(), emailData)
+}
+
+// sendMagicLinkEmail issues a new sign-in link, invalidating older ones
+func (s *AuthService) sendMagicLinkEmail(user *models.User) error {
+	magicToken, err := issueOneTimeToken(database.GetDB(), user, models.TokenPurposeMagicLink, s.cfg.MagicLinkTTL)
+	if err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+
+	magicLink := fmt.Sprintf("%s/magic-link?token=%s", s.cfg.FrontendURL, magicToken)
+
+	emailData := utils.EmailData{
+		To:      user.Email,
+		Subject: "Your Sign-In Link",
+		Body:    utils.GenerateMagicLinkEmail(magicLink, int(s.cfg.MagicLinkTTL.Minutes())),
+	}
+
+	if err := utils.SendEmail(s.emailConfig(), emailData); err != nil {
+		return ErrSignInEmailFailed.Wrap(err)
+	}
+
+	return nil
+}
+
+// sendPasswordResetEmail issues a new single-use reset link, invalidating older ones
+func (s *AuthService) sendPasswordResetEmail(user *models.User) error {
+	resetToken, err := issueOneTimeToken(database.GetDB(), user, models.TokenPurposeResetPassword, 1*time.Hour)
+	if err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+
+	resetLink := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.FrontendURL, resetToken)
+
+	emailData := utils.EmailData{
+		To:      user.Email,
+		Subject: "Reset Your Password",
+		Body:    utils.GenerateResetPasswordEmail(resetLink),
+	}
+
+	if err := utils.SendEmail(s.emailConfig(), emailData); err != nil {
+		return ErrResetEmailFailed.Wrap(err)
+	}
+
+	return nil
+}
+
+// sendVerificationEmail emails a fresh verification link and records when it was sent
+func (s *AuthService) sendVerificationEmail(user *models.User) error {
+	verifyToken, err := utils.GenerateEmailVerificationToken(fmt.Sprintf("%d", user.ID), user.Email, s.cfg.JWTSecret)
+	if err != nil {
+		return err
+	}
+
+	verifyLink := fmt.Sprintf("%s/verify-email?token=%s", s.cfg.FrontendURL, verifyToken)
+
+	emailData := utils.EmailData{
+		To:      user.Email,
+		Subject: "Verify Your Email",
+		Body:    utils.GenerateVerifyEmailEmail(verifyLink),
+	}
+
+	if err := utils.SendEmail(s.emailConfig(), emailData); err != nil {
+		return err
+	}
+
+	return database.GetDB().Model(user).Update("verification_sent_at", time.Now()).Error
+}
+
+func (s *AuthService) emailConfig() utils.EmailConfig {
+	return utils.EmailConfig{
 		SMTPHost:     s.cfg.SMTPHost,
 		SMTPPort:     s.cfg.SMTPPort,
 		SMTPUsername: s.cfg.SMTPUsername,
 		SMTPPassword: s.cfg.SMTPPassword,
 		FromEmail:    s.cfg.FromEmail,
 	}
+}
 
-	emailData := utils.EmailData{
-		To:      user.Email,
-		Subject: "Password Reset Successful",
-		Body:    utils.GeneratePasswordResetSuccessEmail(),
+// completeLogin runs the checks shared by every first factor once the user
+// has been identified, and either issues tokens or starts the MFA challenge
+func (s *AuthService) completeLogin(user *models.User, meta ClientMeta) (*models.LoginResponse, error) {
+	if !user.IsActive {
+		return nil, ErrAccountDeactivated
 	}
 
-	utils.SendEmail(emailConfig, emailData)
+	if s.cfg.EmailVerificationEnforcement == "login" && !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
 
-	return nil
+	if user.MFAEnabled {
+		mfaToken, err := utils.GeneratePurposeToken(fmt.Sprintf("%d", user.ID), user.Email, utils.TokenTypeMFAPending, s.cfg.JWTSecret, 5*time.Minute)
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.LoginResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	return s.issueTokens(user, meta)
+}
+
+// issueTokens starts a new session, whose ID is also the refresh token
+// family, and mints the first access token for it
+func (s *AuthService) issueTokens(user *models.User, meta ClientMeta) (*models.LoginResponse, error) {
+	sessionID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	var refreshToken string
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := createSession(tx, user.ID, sessionID, meta, time.Now().Add(s.cfg.RefreshTokenTTL)); err != nil {
+			return err
+		}
+
+		token, err := s.createRefreshToken(tx, user.ID, sessionID)
+		if err != nil {
+			return err
+		}
+		refreshToken = token
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := utils.GenerateJWT(user.ID, user.Email, string(user.Role), sessionID, JWTConfig(s.cfg), s.cfg.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	userResponse := user.ToResponse()
+	return &models.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.cfg.AccessTokenTTL.Seconds()),
+		User:         &userResponse,
+	}, nil
+}
+
+// createRefreshToken persists the hash of a new opaque refresh token and returns the raw value
+func (s *AuthService) createRefreshToken(db *gorm.DB, userID uint, familyID string) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	refreshToken := models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.cfg.RefreshTokenTTL),
+	}
+
+	if err := db.Create(&refreshToken).Error; err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// revokeRefreshFamily revokes every token that descends from the same login
+func (s *AuthService) revokeRefreshFamily(familyID string) {
+	database.GetDB().Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now())
+
+	// The family is the session, so its access tokens go too
+	if err := revokeSessionByID(database.GetDB(), familyID); err != nil {
+		log.Printf("Failed to revoke session %s: %v", familyID, err)
+	}
 }
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func newTestAuthService(t *testing.T) *AuthService {
+	t.Helper()
+
+	cfg := &config.Config{
+		AppEnv:          "development",
+		JWTSecret:       "test-secret",
+		JWTIssuer:       "blog-app-api",
+		JWTAudience:     "blog-app",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}
+	if err := LoadJWTKeys(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return NewAuthService(cfg)
+}
+
+// signIn starts a session for the user as a successful login would
+func signIn(t *testing.T, service *AuthService, user *models.User) *models.LoginResponse {
+	t.Helper()
+
+	result, err := service.issueTokens(user, ClientMeta{IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("issueTokens: %v", err)
+	}
+	return result
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "refresh@example.com")
+	service := newTestAuthService(t)
+
+	first := signIn(t, service, user)
+	second, err := service.RefreshToken(first.RefreshToken, ClientMeta{})
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.Token == "" {
+		t.Fatal("RefreshToken did not rotate the token")
+	}
+	third, err := service.RefreshToken(second.RefreshToken, ClientMeta{})
+	if err != nil {
+		t.Fatalf("RefreshToken with the rotated token: %v", err)
+	}
+
+	// Replaying a rotated token gives the whole family away
+	if _, err := service.RefreshToken(first.RefreshToken, ClientMeta{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("replayed token: RefreshToken = %v, want %v", err, ErrRefreshTokenReused)
+	}
+	if _, err := service.RefreshToken(third.RefreshToken, ClientMeta{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("latest token after a replay: RefreshToken = %v, want %v", err, ErrRefreshTokenReused)
+	}
+
+	var live int64
+	if err := db.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Count(&live).Error; err != nil {
+		t.Fatal(err)
+	}
+	if live != 0 {
+		t.Errorf("%d sessions still live after a replay, want 0", live)
+	}
+
+	if _, err := service.RefreshToken("unknown", ClientMeta{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("unknown token: RefreshToken = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestRefreshTokenChecksAccount(t *testing.T) {
+	db := testDB(t)
+	service := newTestAuthService(t)
+	service.cfg.EmailVerificationEnforcement = "login"
+
+	verified := time.Now()
+	tests := []struct {
+		email   string
+		updates map[string]interface{}
+		want    error
+	}{
+		{"ok@example.com", map[string]interface{}{"email_verified_at": verified}, nil},
+		{"locked@example.com", map[string]interface{}{"email_verified_at": verified, "locked_until": time.Now().Add(time.Hour)}, ErrTooManyLogins},
+		{"unverified@example.com", map[string]interface{}{}, ErrEmailNotVerified},
+		{"deactivated@example.com", map[string]interface{}{"email_verified_at": verified, "is_active": false}, ErrAccountDeactivated},
+	}
+
+	for _, tt := range tests {
+		user := createTestUser(t, db, tt.email)
+		tokens := signIn(t, service, user)
+		if len(tt.updates) > 0 {
+			if err := db.Model(user).Session(&gorm.Session{}).Updates(tt.updates).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if _, err := service.RefreshToken(tokens.RefreshToken, ClientMeta{}); !errors.Is(err, tt.want) {
+			t.Errorf("%s: RefreshToken = %v, want %v", tt.email, err, tt.want)
+		}
+	}
+}
+
+func TestResetPasswordLinkIsBoundToEmail(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "before@example.com")
+	service := newTestAuthService(t)
+
+	token, err := issueOneTimeToken(db, user, models.TokenPurposeResetPassword, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(user).Update("email", "after@example.com").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// A link sent to the old address is just another invalid link
+	if err := service.ResetPassword(token, "a-Much-Longer-Passphrase-42"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("ResetPassword after an email change = %v, want %v", err, ErrInvalidResetToken)
+	}
+}
+
+func TestMagicLinkKeptForLockedAccounts(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "magic@example.com")
+	service := newTestAuthService(t)
+
+	token, err := issueOneTimeToken(db, user, models.TokenPurposeMagicLink, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Model(user).Update("locked_until", time.Now().Add(time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.VerifyMagicLink(token, ClientMeta{}); !errors.Is(err, ErrTooManyLogins) {
+		t.Errorf("locked account: VerifyMagicLink = %v, want %v", err, ErrTooManyLogins)
+	}
+
+	if err := db.Model(user).Updates(map[string]interface{}{"locked_until": nil, "is_active": false}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.VerifyMagicLink(token, ClientMeta{}); !errors.Is(err, ErrAccountDeactivated) {
+		t.Errorf("deactivated account: VerifyMagicLink = %v, want %v", err, ErrAccountDeactivated)
+	}
+
+	// Neither refusal used the link up
+	if err := db.Model(user).Update("is_active", true).Error; err != nil {
+		t.Fatal(err)
+	}
+	result, err := service.VerifyMagicLink(token, ClientMeta{})
+	if err != nil {
+		t.Fatalf("VerifyMagicLink: %v", err)
+	}
+	if result.Token == "" {
+		t.Error("no access token issued")
+	}
+	if _, err := service.VerifyMagicLink(token, ClientMeta{}); !errors.Is(err, ErrInvalidMagicLink) {
+		t.Errorf("second use: VerifyMagicLink = %v, want %v", err, ErrInvalidMagicLink)
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+
+	"github.com/gosimple/slug"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recordRevision snapshots the blog as just saved. It runs after the blog row
+// is written, so the row lock makes concurrent saves number their revisions
+// one after the other.
+func recordRevision(tx *gorm.DB, blog *models.Blog, editorID uint) error {
+	var last int
+	if err := tx.Model(&models.BlogRevision{}).
+		Where("blog_id = ?", blog.ID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&last).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&models.BlogRevision{
+		BlogID:    blog.ID,
+		Number:    last + 1,
+		Title:     blog.Title,
+		Content:   blog.Content,
+		Published: blog.Published,
+		EditorID:  editorID,
+	}).Error
+}
+
+// recordBaselineRevision keeps the state of a blog written before revisions
+// existed, so its first edit can still be undone. The author is credited.
+func recordBaselineRevision(db *gorm.DB, blog *models.Blog) error {
+	var count int64
+	if err := db.Model(&models.BlogRevision{}).Where("blog_id = ?", blog.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.BlogRevision{
+		BlogID:    blog.ID,
+		Number:    1,
+		Title:     blog.Title,
+		Content:   blog.Content,
+		Published: blog.Published,
+		EditorID:  blog.UserID,
+		CreatedAt: blog.UpdatedAt,
+	}).Error
+}
+
+// blogForRevisions loads a blog whose history the actor may read, which is
+// anyone allowed to edit it
+func blogForRevisions(actor Actor, id uint) (*models.Blog, error) {
+	var blog models.Blog
+	if err := database.GetDB().First(&blog, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBlogNotFound
+		}
+		return nil, err
+	}
+	if !canUpdateBlog(actor, &blog) {
+		return nil, ErrBlogRevisionsForbidden
+	}
+	return &blog, nil
+}
+
+func (s *BlogService) GetRevisions(actor Actor, id uint, page, limit int) ([]models.BlogRevision, int64, error) {
+	if _, err := blogForRevisions(actor, id); err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := database.GetDB().Model(&models.BlogRevision{}).Where("blog_id = ?", id).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var revisions []models.BlogRevision
+	if err := database.GetDB().
+		Preload("Editor").
+		Where("blog_id = ?", id).
+		Order("number DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&revisions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+func (s *BlogService) GetRevision(actor Actor, id uint, number int) (*models.BlogRevision, error) {
+	if _, err := blogForRevisions(actor, id); err != nil {
+		return nil, err
+	}
+	return findRevision(id, number)
+}
+
+// DiffRevisions compares the title and content of two revisions of a blog
+func (s *BlogService) DiffRevisions(actor Actor, id uint, query models.BlogRevisionDiffQuery) (*models.BlogRevisionDiffResponse, error) {
+	if _, err := blogForRevisions(actor, id); err != nil {
+		return nil, err
+	}
+
+	from, err := findRevision(id, query.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := findRevision(id, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := utils.DiffLines
+	mode := models.DiffModeLine
+	if query.Mode == models.DiffModeWord {
+		diff = utils.DiffWords
+		mode = models.DiffModeWord
+	}
+
+	return &models.BlogRevisionDiffResponse{
+		From:    from.ToSummary(),
+		To:      to.ToSummary(),
+		Mode:    mode,
+		Title:   utils.DiffWords(from.Title, to.Title),
+		Content: diff(from.Content, to.Content),
+	}, nil
+}
+
+// RestoreRevision brings back the title and content of an earlier revision.
+// History is never rewritten: the restored state is saved as a new revision.
+// The published flag is left alone, since changing it is a separate right.
+func (s *BlogService) RestoreRevision(actor Actor, id uint, number int) (*models.Blog, error) {
+	blog, err := blogForRevisions(actor, id)
+	if err != nil {
+		if errors.Is(err, ErrBlogRevisionsForbidden) {
+			return nil, ErrBlogUpdateForbidden
+		}
+		return nil, err
+	}
+
+	revision, err := findRevision(id, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordBaselineRevision(database.GetDB(), blog); err != nil {
+		return nil, err
+	}
+
+	var slugBase string
+	if revision.Title != blog.Title {
+		slugBase = slug.Make(revision.Title)
+	}
+	blog.Title = revision.Title
+	blog.Content = revision.Content
+
+	if err := saveBlog(blog, slugBase, "", blog.Slug, actor.UserID); err != nil {
+		return nil, err
+	}
+
+	withBlogRelations(database.GetDB()).First(blog, blog.ID)
+	return blog, nil
+}
+
+func findRevision(blogID uint, number int) (*models.BlogRevision, error) {
+	var revision models.BlogRevision
+	if err := database.GetDB().
+		Preload("Editor").
+		Where("blog_id = ? AND number = ?", blogID, number).
+		First(&revision).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBlogRevisionNotFound
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
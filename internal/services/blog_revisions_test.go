@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+)
+
+func revisionNumbers(t *testing.T, actor Actor, blogID uint) []int {
+	t.Helper()
+
+	revisions, total, err := newTestBlogService().GetRevisions(actor, blogID, 1, 100)
+	if err != nil {
+		t.Fatalf("GetRevisions: %v", err)
+	}
+	if total != int64(len(revisions)) {
+		t.Errorf("GetRevisions: total %d, listed %d", total, len(revisions))
+	}
+	numbers := make([]int, len(revisions))
+	for i, revision := range revisions {
+		numbers[i] = revision.Number
+	}
+	return numbers
+}
+
+func TestRevisionsDiffAndRestore(t *testing.T) {
+	db := testDB(t)
+	author := createTestUser(t, db, "author@example.com")
+	editor := createTestUser(t, db, "editor@example.com")
+	editor.Role = models.RoleEditor
+	service := newTestBlogService()
+
+	blog := createTestBlog(t, author, models.CreateBlogRequest{Title: "Original", Content: "one\ntwo\n", Published: true})
+	if _, err := service.UpdateBlog(blog.ID, actorFor(author), models.UpdateBlogRequest{Title: "Edited", Content: "one\n2\n"}); err != nil {
+		t.Fatalf("UpdateBlog: %v", err)
+	}
+	if got := revisionNumbers(t, actorFor(author), blog.ID); !reflect.DeepEqual(got, []int{2, 1}) {
+		t.Fatalf("revisions = %v, want [2 1]", got)
+	}
+
+	diff, err := service.DiffRevisions(actorFor(author), blog.ID, models.BlogRevisionDiffQuery{From: 1, To: 2})
+	if err != nil {
+		t.Fatalf("DiffRevisions: %v", err)
+	}
+	wantTitle := []utils.DiffChunk{{Op: utils.DiffDelete, Text: "Original"}, {Op: utils.DiffInsert, Text: "Edited"}}
+	wantContent := []utils.DiffChunk{
+		{Op: utils.DiffEqual, Text: "one\n"},
+		{Op: utils.DiffDelete, Text: "two\n"},
+		{Op: utils.DiffInsert, Text: "2\n"},
+	}
+	if diff.Mode != models.DiffModeLine || !reflect.DeepEqual(diff.Title, wantTitle) || !reflect.DeepEqual(diff.Content, wantContent) {
+		t.Errorf("line diff = %s %q %q, want %s %q %q", diff.Mode, diff.Title, diff.Content, models.DiffModeLine, wantTitle, wantContent)
+	}
+
+	diff, err = service.DiffRevisions(actorFor(author), blog.ID, models.BlogRevisionDiffQuery{From: 2, To: 1, Mode: models.DiffModeWord})
+	if err != nil {
+		t.Fatalf("DiffRevisions: %v", err)
+	}
+	wantContent = []utils.DiffChunk{
+		{Op: utils.DiffEqual, Text: "one\n"},
+		{Op: utils.DiffDelete, Text: "2"},
+		{Op: utils.DiffInsert, Text: "two"},
+		{Op: utils.DiffEqual, Text: "\n"},
+	}
+	if diff.Mode != models.DiffModeWord || !reflect.DeepEqual(diff.Content, wantContent) {
+		t.Errorf("word diff = %s %q, want %s %q", diff.Mode, diff.Content, models.DiffModeWord, wantContent)
+	}
+
+	// Restoring saves a new revision and takes back the old slug
+	restored, err := service.RestoreRevision(actorFor(editor), blog.ID, 1)
+	if err != nil {
+		t.Fatalf("RestoreRevision: %v", err)
+	}
+	if restored.Title != "Original" || restored.Content != "one\ntwo\n" || restored.Slug != "original" || !restored.Published {
+		t.Errorf("restored blog = %q %q %q published %v", restored.Title, restored.Content, restored.Slug, restored.Published)
+	}
+	if got := revisionNumbers(t, actorFor(author), blog.ID); !reflect.DeepEqual(got, []int{3, 2, 1}) {
+		t.Fatalf("revisions after restore = %v, want [3 2 1]", got)
+	}
+	revision, err := service.GetRevision(actorFor(author), blog.ID, 3)
+	if err != nil {
+		t.Fatalf("GetRevision: %v", err)
+	}
+	if revision.Title != "Original" || revision.EditorID != editor.ID {
+		t.Errorf("revision 3 = %q by user %d, want %q by user %d", revision.Title, revision.EditorID, "Original", editor.ID)
+	}
+}
+
+func TestRevisionsNeedEditRights(t *testing.T) {
+	db := testDB(t)
+	author := createTestUser(t, db, "author@example.com")
+	other := createTestUser(t, db, "other@example.com")
+	service := newTestBlogService()
+
+	blog := createTestBlog(t, author, models.CreateBlogRequest{Title: "Mine", Published: true})
+
+	if _, _, err := service.GetRevisions(actorFor(other), blog.ID, 1, 10); !errors.Is(err, ErrBlogRevisionsForbidden) {
+		t.Errorf("GetRevisions by another author = %v, want %v", err, ErrBlogRevisionsForbidden)
+	}
+	if _, err := service.DiffRevisions(actorFor(other), blog.ID, models.BlogRevisionDiffQuery{From: 1, To: 1}); !errors.Is(err, ErrBlogRevisionsForbidden) {
+		t.Errorf("DiffRevisions by another author = %v, want %v", err, ErrBlogRevisionsForbidden)
+	}
+	if _, err := service.RestoreRevision(actorFor(other), blog.ID, 1); !errors.Is(err, ErrBlogUpdateForbidden) {
+		t.Errorf("RestoreRevision by another author = %v, want %v", err, ErrBlogUpdateForbidden)
+	}
+
+	if _, err := service.GetRevision(actorFor(author), blog.ID, 2); !errors.Is(err, ErrBlogRevisionNotFound) {
+		t.Errorf("GetRevision of a missing revision = %v, want %v", err, ErrBlogRevisionNotFound)
+	}
+	if _, err := service.RestoreRevision(actorFor(author), blog.ID+1, 1); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("RestoreRevision of a missing blog = %v, want %v", err, ErrBlogNotFound)
+	}
+}
+
+func TestFirstEditKeepsBaselineRevision(t *testing.T) {
+	db := testDB(t)
+	author := createTestUser(t, db, "author@example.com")
+	admin := createTestUser(t, db, "admin@example.com")
+	admin.Role = models.RoleAdmin
+
+	// A blog written before revisions existed has none
+	blog := &models.Blog{Title: "Old post", Slug: "old-post", Content: "Before", UserID: author.ID, Published: true}
+	if err := db.Create(blog).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newTestBlogService().UpdateBlog(blog.ID, actorFor(admin), models.UpdateBlogRequest{Content: "After"}); err != nil {
+		t.Fatalf("UpdateBlog: %v", err)
+	}
+
+	revisions, _, err := newTestBlogService().GetRevisions(actorFor(author), blog.ID, 1, 10)
+	if err != nil {
+		t.Fatalf("GetRevisions: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("got %d revisions, want 2", len(revisions))
+	}
+	if baseline := revisions[1]; baseline.Number != 1 || baseline.Content != "Before" || baseline.EditorID != author.ID {
+		t.Errorf("baseline = #%d %q by user %d, want #1 %q by user %d", baseline.Number, baseline.Content, baseline.EditorID, "Before", author.ID)
+	}
+	if edit := revisions[0]; edit.Number != 2 || edit.Content != "After" || edit.EditorID != admin.ID {
+		t.Errorf("edit = #%d %q by user %d, want #2 %q by user %d", edit.Number, edit.Content, edit.EditorID, "After", admin.ID)
+	}
+}
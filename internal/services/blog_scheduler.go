@@ -0,0 +1,114 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+
+	"gorm.io/gorm"
+)
+
+// blogSchedulerLockKey identifies the advisory lock that keeps API instances
+// from running the scheduler at the same time ("blog" in ASCII)
+const blogSchedulerLockKey = 0x626c6f67
+
+// StartBlogScheduler publishes and unpublishes blogs whose scheduled time has
+// come. Every API instance runs it; the advisory lock lets one at a time work.
+func StartBlogScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := RunBlogSchedule(time.Now()); err != nil {
+				log.Printf("Failed to run blog schedule: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// RunBlogSchedule carries out every scheduled change due by now. A run that
+// finds another instance holding the lock does nothing; that one covers it.
+func RunBlogSchedule(now time.Time) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		// A transaction-level lock is tied to this connection and released on
+		// commit, so it cannot leak back into the pool
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", blogSchedulerLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		// Publishing goes first, so a blog whose whole window passed while no
+		// instance was running ends up unpublished
+		published := tx.Model(&models.Blog{}).
+			Where("publish_at <= ?", now).
+			Updates(map[string]interface{}{
+				"published":    true,
+				"published_at": gorm.Expr("COALESCE(published_at, publish_at)"),
+				"publish_at":   nil,
+			})
+		if published.Error != nil {
+			return published.Error
+		}
+
+		unpublished := tx.Model(&models.Blog{}).
+			Where("unpublish_at <= ?", now).
+			Updates(map[string]interface{}{
+				"published":    false,
+				"unpublish_at": nil,
+			})
+		if unpublished.Error != nil {
+			return unpublished.Error
+		}
+
+		if published.RowsAffected > 0 || unpublished.RowsAffected > 0 {
+			log.Printf("Blog schedule: published %d, unpublished %d", published.RowsAffected, unpublished.RowsAffected)
+		}
+		return nil
+	})
+}
+
+// applySchedule updates a blog's publication state from a create or update
+// request. Sending published replaces any pending schedule with what the
+// request says; a publish_at that has already passed publishes right away.
+func applySchedule(blog *models.Blog, published *bool, publishAt, unpublishAt string, now time.Time) error {
+	if published != nil {
+		blog.Published = *published
+		blog.PublishAt = nil
+		blog.UnpublishAt = nil
+	}
+
+	if publishAt != "" {
+		at, _, err := utils.ParseDate(publishAt)
+		if err != nil {
+			return ErrInvalidSchedule
+		}
+		if at.After(now) {
+			blog.Published = false
+			blog.PublishAt = &at
+		} else {
+			blog.Published = true
+			blog.PublishAt = nil
+		}
+	}
+
+	if unpublishAt != "" {
+		at, _, err := utils.ParseDate(unpublishAt)
+		if err != nil || !at.After(now) || (blog.PublishAt != nil && !at.After(*blog.PublishAt)) {
+			return ErrInvalidSchedule
+		}
+		blog.UnpublishAt = &at
+	}
+
+	if blog.Published && blog.PublishedAt == nil {
+		blog.PublishedAt = &now
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+)
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func TestApplySchedule(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(hours int) *time.Time {
+		t := now.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+	stamp := func(hours int) string { return at(hours).Format(time.RFC3339) }
+	yes, no := true, false
+
+	tests := []struct {
+		name        string
+		blog        models.Blog
+		published   *bool
+		publishAt   string
+		unpublishAt string
+		want        models.Blog
+		err         error
+	}{
+		{
+			name:      "publish now",
+			published: &yes,
+			want:      models.Blog{Published: true, PublishedAt: &now},
+		},
+		{
+			name:      "draft",
+			published: &no,
+			want:      models.Blog{},
+		},
+		{
+			name:      "future publish_at waits",
+			publishAt: stamp(2),
+			want:      models.Blog{PublishAt: at(2)},
+		},
+		{
+			name:      "past publish_at publishes now",
+			publishAt: stamp(-2),
+			want:      models.Blog{Published: true, PublishedAt: &now},
+		},
+		{
+			name:        "publication window",
+			publishAt:   stamp(2),
+			unpublishAt: stamp(4),
+			want:        models.Blog{PublishAt: at(2), UnpublishAt: at(4)},
+		},
+		{
+			name:        "unpublish a live blog later",
+			blog:        models.Blog{Published: true, PublishedAt: at(-24)},
+			unpublishAt: stamp(4),
+			want:        models.Blog{Published: true, PublishedAt: at(-24), UnpublishAt: at(4)},
+		},
+		{
+			name:      "published replaces the schedule",
+			blog:      models.Blog{PublishAt: at(2), UnpublishAt: at(4)},
+			published: &no,
+			want:      models.Blog{},
+		},
+		{
+			name:      "republishing keeps the first published_at",
+			blog:      models.Blog{PublishedAt: at(-24)},
+			published: &yes,
+			want:      models.Blog{Published: true, PublishedAt: at(-24)},
+		},
+		{
+			name:        "unpublish_at before publish_at",
+			publishAt:   stamp(4),
+			unpublishAt: stamp(2),
+			err:         ErrInvalidSchedule,
+		},
+		{
+			name:        "unpublish_at in the past",
+			unpublishAt: stamp(-1),
+			err:         ErrInvalidSchedule,
+		},
+		{
+			name:      "unparsable publish_at",
+			publishAt: "next week",
+			err:       ErrInvalidSchedule,
+		},
+	}
+
+	for _, tt := range tests {
+		blog := tt.blog
+		err := applySchedule(&blog, tt.published, tt.publishAt, tt.unpublishAt, now)
+		if tt.err != nil || err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+			}
+			continue
+		}
+
+		if blog.Published != tt.want.Published ||
+			!sameTime(blog.PublishAt, tt.want.PublishAt) ||
+			!sameTime(blog.UnpublishAt, tt.want.UnpublishAt) ||
+			!sameTime(blog.PublishedAt, tt.want.PublishedAt) {
+			t.Errorf("%s: published %v, publish_at %v, unpublish_at %v, published_at %v; want %v, %v, %v, %v", tt.name,
+				blog.Published, blog.PublishAt, blog.UnpublishAt, blog.PublishedAt,
+				tt.want.Published, tt.want.PublishAt, tt.want.UnpublishAt, tt.want.PublishedAt)
+		}
+	}
+}
+
+func TestRunBlogSchedule(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "scheduler@example.com")
+
+	now := time.Now().Truncate(time.Second)
+	at := func(hours int) *time.Time {
+		t := now.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+
+	blogs := map[string]*models.Blog{
+		"due":          {PublishAt: at(-1)},
+		"not yet due":  {PublishAt: at(1)},
+		"expired":      {Published: true, PublishedAt: at(-24), UnpublishAt: at(-1)},
+		"still live":   {Published: true, PublishedAt: at(-24), UnpublishAt: at(1)},
+		"window over":  {PublishAt: at(-2), UnpublishAt: at(-1)},
+		"window opens": {PublishAt: at(-1), UnpublishAt: at(1)},
+	}
+	for title, blog := range blogs {
+		blog.Title, blog.Slug, blog.Content, blog.UserID = title, title, title, user.ID
+		if err := db.Create(blog).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := RunBlogSchedule(now); err != nil {
+		t.Fatalf("RunBlogSchedule: %v", err)
+	}
+
+	tests := []struct {
+		title       string
+		published   bool
+		publishedAt *time.Time
+		pending     bool
+	}{
+		{"due", true, at(-1), false},
+		{"not yet due", false, nil, true},
+		{"expired", false, at(-24), false},
+		{"still live", true, at(-24), true},
+		{"window over", false, at(-2), false},
+		{"window opens", true, at(-1), true},
+	}
+	for _, tt := range tests {
+		var blog models.Blog
+		if err := db.First(&blog, blogs[tt.title].ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		pending := blog.PublishAt != nil || blog.UnpublishAt != nil
+		if blog.Published != tt.published || !sameTime(blog.PublishedAt, tt.publishedAt) || pending != tt.pending {
+			t.Errorf("%s: published %v, published_at %v, pending %v; want %v, %v, %v", tt.title,
+				blog.Published, blog.PublishedAt, pending, tt.published, tt.publishedAt, tt.pending)
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"go-fiber-boilerplate/database"
 	"go-fiber-boilerplate/internal/models"
 	"go-fiber-boilerplate/utils"
+	"strings"
+	"time"
 
 	"github.com/gosimple/slug"
 	"gorm.io/gorm"
@@ -29,94 +31,252 @@ func NewBlogService(cfg *config.Config) (*BlogService, error) {
 }
 
 func (s *BlogService) CreateBlog(userID uint, req models.CreateBlogRequest) (*models.Blog, error) {
-	var existingBlog models.Blog
-	if err := database.GetDB().Where("title = ?", req.Title).First(&existingBlog).Error; err == nil {
-		return nil, errors.New("title already exists")
+	if s.cfg.EmailVerificationEnforcement == "blog" || s.cfg.EmailVerificationEnforcement == "login" {
+		var user models.User
+		if err := database.GetDB().First(&user, userID).Error; err != nil {
+			return nil, err
+		}
+		if !user.IsEmailVerified() {
+			return nil, ErrEmailNotVerified
+		}
 	}
 
-	generatedSlug := slug.Make(req.Title)
-
 	blog := models.Blog{
-		Title:     req.Title,
-		Content:   req.Content,
-		UserID:    userID,
-		Slug:      generatedSlug,
-		Published: req.Published,
+		Title:   req.Title,
+		Content: req.Content,
+		UserID:  userID,
+	}
+	if err := applySchedule(&blog, &req.Published, req.PublishAt, req.UnpublishAt, time.Now()); err != nil {
+		return nil, err
+	}
+
+	tags, err := resolveTags(database.GetDB(), req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	blog.Tags = tags
+
+	if blog.CategoryID, err = resolveCategory(database.GetDB(), req.Category); err != nil {
+		return nil, err
 	}
 
 	if req.Image != nil {
 		uploadResult, err := s.cloudinary.UploadImage(req.Image, "blog-images")
 		if err != nil {
-			return nil, fmt.Errorf("failed to upload image: %w", err)
+			return nil, ErrImageUploadFailed.Wrap(err)
 		}
 		blog.ImageURL = uploadResult.SecureURL
 		blog.ImageID = uploadResult.PublicID
 	}
 
-	if err := database.GetDB().Create(&blog).Error; err != nil {
+	if err := saveBlog(&blog, slug.Make(req.Title), req.Slug, "", userID); err != nil {
 		if blog.ImageID != "" {
 			_ = s.cloudinary.DeleteImage(blog.ImageID)
 		}
 		return nil, err
 	}
 
-	database.GetDB().Preload("User").First(&blog, blog.ID)
+	withBlogRelations(database.GetDB()).First(&blog, blog.ID)
 	return &blog, nil
 }
 
-func (s *BlogService) GetBlogs(page, limit int) ([]models.Blog, int64, error) {
-	var blogs []models.Blog
-	offset := (page - 1) * limit
+// blogSearchConfig must match the configuration of the search_vector column
+const blogSearchConfig = "simple"
 
-	if err := database.GetDB().
-		Preload("User").
-		Offset(offset).
-		Limit(limit).
-		Find(&blogs).Error; err != nil {
-		return nil, 0, err
+// blogHeadlineOptions marks matches in the snippet and keeps it short
+const blogHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+
+// blogSnippetSource is the content with HTML escaped. The snippet is sent as
+// HTML for the sake of its <mark> tags, so nothing else in it may be markup.
+const blogSnippetSource = `replace(replace(replace(replace(replace(blogs.content, ` +
+	`'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+// GetBlogs lists the blogs the viewer may see, optionally narrowed by a
+// full-text search and filters. Drafts are only listed for their author and admins.
+func (s *BlogService) GetBlogs(viewer Actor, params models.BlogQueryParams) ([]models.Blog, int64, error) {
+	query := database.GetDB().Model(&models.Blog{})
+	if !viewer.Can(models.PermissionBlogReadAny) {
+		query = query.Where("(blogs.published = ? OR blogs.user_id = ?)", true, viewer.UserID)
+	}
+
+	if params.Published != nil {
+		query = query.Where("blogs.published = ?", *params.Published)
+	}
+	if params.UserID != 0 {
+		query = query.Where("blogs.user_id = ?", params.UserID)
+	}
+	if params.Tag != "" {
+		query = query.Where(`EXISTS (SELECT 1 FROM blog_tags JOIN tags ON tags.id = blog_tags.tag_id
+			WHERE blog_tags.blog_id = blogs.id AND tags.slug = ?)`, params.Tag)
+	}
+	if params.Category != "" {
+		// A category also lists the blogs of its subcategories
+		query = query.Where(`blogs.category_id IN (WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE slug = ?
+				UNION
+				SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+			) SELECT id FROM tree)`, params.Category)
+	}
+
+	if params.From != "" {
+		from, _, err := utils.ParseDate(params.From)
+		if err != nil {
+			return nil, 0, ErrInvalidDateRange
+		}
+		query = query.Where("blogs.created_at >= ?", from)
+	}
+	if params.To != "" {
+		to, dateOnly, err := utils.ParseDate(params.To)
+		if err != nil {
+			return nil, 0, ErrInvalidDateRange
+		}
+		// A bare date includes the whole day
+		if dateOnly {
+			query = query.Where("blogs.created_at < ?", to.AddDate(0, 0, 1))
+		} else {
+			query = query.Where("blogs.created_at <= ?", to)
+		}
 	}
 
+	search := strings.TrimSpace(params.Search)
+	if search != "" {
+		query = query.Where("blogs.search_vector @@ websearch_to_tsquery(?, ?)", blogSearchConfig, search)
+	}
+
+	// Count and Find each need their own copy of the filtered statement
+	query = query.Session(&gorm.Session{})
+
 	var total int64
-	if err := database.GetDB().Model(&models.Blog{}).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	list := withBlogRelations(query)
+	if search != "" {
+		list = list.Select(
+			"blogs.*, ts_rank(blogs.search_vector, websearch_to_tsquery(?, ?)) AS search_rank, "+
+				"ts_headline(?, "+blogSnippetSource+", websearch_to_tsquery(?, ?), ?) AS snippet",
+			blogSearchConfig, search, blogSearchConfig, blogSearchConfig, search, blogHeadlineOptions,
+		)
+	}
+
+	var blogs []models.Blog
+	if err := list.
+		Order(blogOrder(params.Sort, search != "")).
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&blogs).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return blogs, total, nil
 }
 
-func (s *BlogService) GetBlogById(id uint) (*models.Blog, error) {
-	var blog models.Blog
-	if err := database.GetDB().
+// blogOrder defaults to relevance for searches and newest first otherwise.
+// Blogs are dated by when they went live; drafts by when they were written.
+func blogOrder(sort string, searching bool) string {
+	if sort == "" || (sort == models.BlogSortRelevance && !searching) {
+		sort = models.BlogSortNewest
+		if searching {
+			sort = models.BlogSortRelevance
+		}
+	}
+
+	switch sort {
+	case models.BlogSortRelevance:
+		return "search_rank DESC, COALESCE(blogs.published_at, blogs.created_at) DESC, blogs.id DESC"
+	case models.BlogSortOldest:
+		return "COALESCE(blogs.published_at, blogs.created_at) ASC, blogs.id ASC"
+	case models.BlogSortTitle:
+		return "blogs.title ASC, blogs.id ASC"
+	}
+	return "COALESCE(blogs.published_at, blogs.created_at) DESC, blogs.id DESC"
+}
+
+// withBlogRelations loads everything a BlogResponse shows
+func withBlogRelations(db *gorm.DB) *gorm.DB {
+	return db.
 		Preload("User").
+		Preload("Category").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB {
+			return db.Order("tags.name ASC")
+		})
+}
+
+// GetBlogBySlug finds a blog by its current slug or, failing that, by one it
+// used to have, in which case moved is true and the caller should redirect
+func (s *BlogService) GetBlogBySlug(viewer Actor, value string) (blog *models.Blog, moved bool, err error) {
+	var found models.Blog
+	err = withBlogRelations(database.GetDB()).Where("slug = ?", value).First(&found).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var history models.BlogSlug
+		if err := database.GetDB().Where("slug = ?", value).First(&history).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, false, ErrBlogNotFound
+			}
+			return nil, false, err
+		}
+		moved = true
+		err = withBlogRelations(database.GetDB()).First(&found, history.BlogID).Error
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, ErrBlogNotFound
+		}
+		return nil, false, err
+	}
+
+	if !canViewBlog(viewer, &found) {
+		return nil, false, ErrBlogNotFound
+	}
+	return &found, moved, nil
+}
+
+// GetBlogById reports drafts the viewer may not see as not found, so their
+// existence is not leaked
+func (s *BlogService) GetBlogById(viewer Actor, id uint) (*models.Blog, error) {
+	var blog models.Blog
+	if err := withBlogRelations(database.GetDB()).
 		First(&blog, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, gorm.ErrRecordNotFound
+			return nil, ErrBlogNotFound
 		}
 		return nil, err
 	}
+	if !canViewBlog(viewer, &blog) {
+		return nil, ErrBlogNotFound
+	}
 	return &blog, nil
 }
 
-func (s *BlogService) UpdateBlog(id uint, userId uint, req models.UpdateBlogRequest) (*models.Blog, error) {
+func (s *BlogService) UpdateBlog(id uint, actor Actor, req models.UpdateBlogRequest) (*models.Blog, error) {
 	var blog models.Blog
 	if err := database.GetDB().First(&blog, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, gorm.ErrRecordNotFound
+			return nil, ErrBlogNotFound
 		}
 		return nil, err
 	}
 
-	if blog.UserID != userId {
-		return nil, errors.New("unauthorized to update this blog")
+	editsContent := req.Title != "" || req.Content != "" || req.Slug != "" || req.Image != nil ||
+		req.Tags != nil || req.Category != nil
+	changesPublication := req.Published != nil || req.PublishAt != "" || req.UnpublishAt != ""
+	if (editsContent || !changesPublication) && !canUpdateBlog(actor, &blog) {
+		return nil, ErrBlogUpdateForbidden
+	}
+
+	if changesPublication && !canPublishBlog(actor, &blog) {
+		return nil, ErrBlogPublishForbidden
+	}
+
+	if err := recordBaselineRevision(database.GetDB(), &blog); err != nil {
+		return nil, err
 	}
 
+	// A new title gets a new slug, unless a custom one is given
+	var slugBase string
 	if req.Title != "" && req.Title != blog.Title {
-		generatedSlug := slug.Make(req.Title)
-		var existingBlog models.Blog
-		if err := database.GetDB().Where("title = ? AND id != ?", req.Title, id).First(&existingBlog).Error; err == nil {
-			return nil, errors.New("title already exists")
-		}
-		blog.Slug = generatedSlug
+		slugBase = slug.Make(req.Title)
 		blog.Title = req.Title
 	}
 
@@ -124,48 +284,66 @@ func (s *BlogService) UpdateBlog(id uint, userId uint, req models.UpdateBlogRequ
 		blog.Content = req.Content
 	}
 
-	if req.Published != nil {
-		blog.Published = *req.Published
+	if req.Tags != nil {
+		tags, err := resolveTags(database.GetDB(), *req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		blog.Tags = tags
+	}
+
+	if req.Category != nil {
+		categoryID, err := resolveCategory(database.GetDB(), *req.Category)
+		if err != nil {
+			return nil, err
+		}
+		blog.CategoryID = categoryID
+	}
+
+	if changesPublication {
+		if err := applySchedule(&blog, req.Published, req.PublishAt, req.UnpublishAt, time.Now()); err != nil {
+			return nil, err
+		}
 	}
 
 	if req.Image != nil {
 		if blog.ImageID != "" {
 			err := s.cloudinary.DeleteImage(blog.ImageID)
 			if err != nil {
-				return nil, fmt.Errorf("failed to delete old image: %w", err)
+				return nil, ErrImageUploadFailed.Wrap(err)
 			}
 		}
 
 		uploadResult, err := s.cloudinary.UploadImage(req.Image, "blog-images")
 		if err != nil {
-			return nil, fmt.Errorf("failed to upload new image: %w", err)
+			return nil, ErrImageUploadFailed.Wrap(err)
 		}
 		blog.ImageURL = uploadResult.SecureURL
 		blog.ImageID = uploadResult.PublicID
 	}
 
-	if err := database.GetDB().Save(&blog).Error; err != nil {
+	if err := saveBlog(&blog, slugBase, req.Slug, blog.Slug, actor.UserID); err != nil {
 		if req.Image != nil && blog.ImageID != "" {
 			_ = s.cloudinary.DeleteImage(blog.ImageID)
 		}
 		return nil, err
 	}
 
-	database.GetDB().Preload("User").First(&blog, blog.ID)
+	withBlogRelations(database.GetDB()).First(&blog, blog.ID)
 	return &blog, nil
 }
 
-func (s *BlogService) DeleteBlog(id uint, userId uint) error {
+func (s *BlogService) DeleteBlog(id uint, actor Actor) error {
 	var blog models.Blog
 	if err := database.GetDB().First(&blog, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return gorm.ErrRecordNotFound
+			return ErrBlogNotFound
 		}
 		return err
 	}
 
-	if blog.UserID != userId {
-		return errors.New("unauthorized to delete this blog")
+	if !canDeleteBlog(actor, &blog) {
+		return ErrBlogDeleteForbidden
 	}
 
 	if err := database.GetDB().Delete(&blog).Error; err != nil {
@@ -178,3 +356,166 @@ func (s *BlogService) DeleteBlog(id uint, userId uint) error {
 
 	return nil
 }
+
+// maxSlugAttempts bounds the retries when concurrent saves race for a slug
+const maxSlugAttempts = 5
+
+// saveBlog creates or updates a blog together with its slug. A custom slug is
+// used as is; otherwise, when slugBase is set or the blog has no slug yet, the
+// first free slug derived from it is taken. Losing a race for a derived slug
+// to a concurrent save retries with the next free one. The slug being
+// replaced goes to the blog's history, and the saved state becomes a new
+// revision credited to editorID.
+func saveBlog(blog *models.Blog, slugBase, customSlug, previousSlug string, editorID uint) error {
+	// Decided once: after a lost race blog.Slug holds the slug that was taken
+	deriveSlug := customSlug == "" && (slugBase != "" || blog.Slug == "")
+
+	for attempt := 1; ; attempt++ {
+		err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+			switch {
+			case customSlug != "":
+				if customSlug != previousSlug {
+					if err := checkCustomSlug(tx, customSlug, blog.ID); err != nil {
+						return err
+					}
+				}
+				blog.Slug = customSlug
+			case deriveSlug:
+				free, err := uniqueSlug(tx, slugBase, blog.ID)
+				if err != nil {
+					return err
+				}
+				blog.Slug = free
+			}
+
+			if blog.Slug != previousSlug {
+				if err := releaseOldSlug(tx, blog.Slug); err != nil {
+					return err
+				}
+				if previousSlug != "" {
+					if err := tx.Create(&models.BlogSlug{BlogID: blog.ID, Slug: previousSlug}).Error; err != nil {
+						return err
+					}
+				}
+			}
+			// Tags are replaced as a set below rather than upserted one by one
+			if err := tx.Omit("Tags").Save(blog).Error; err != nil {
+				// The translated error no longer names the index, but the
+				// only unique one on blogs is idx_blogs_slug
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					return ErrBlogSlugTaken.Wrap(err)
+				}
+				return err
+			}
+			if blog.Tags != nil {
+				if err := tx.Model(blog).Association("Tags").Replace(blog.Tags); err != nil {
+					return err
+				}
+			}
+			return recordRevision(tx, blog, editorID)
+		})
+
+		if errors.Is(err, ErrBlogSlugTaken) && deriveSlug && attempt < maxSlugAttempts {
+			continue
+		}
+		// Any other unique index, e.g. the slug history, is not a slug
+		// the caller can pick differently
+		if errors.Is(err, gorm.ErrDuplicatedKey) && !errors.Is(err, ErrBlogSlugTaken) {
+			return ErrDatabase.Wrap(err)
+		}
+		return err
+	}
+}
+
+// uniqueSlug returns base, or base with the lowest free numeric suffix
+// ("my-post-2", "my-post-3", ...) when other blogs already use it
+func uniqueSlug(db *gorm.DB, base string, blogID uint) (string, error) {
+	if base == "" {
+		base = "post"
+	}
+	// Leave room for the suffix within the column size
+	if len(base) > 240 {
+		base = strings.TrimRight(base[:240], "-")
+	}
+
+	pattern := strings.ReplaceAll(base, "_", `\_`) + "-%"
+
+	var used []string
+	if err := db.Model(&models.Blog{}).
+		Where("(slug = ? OR slug LIKE ?) AND id != ?", base, pattern, blogID).
+		Pluck("slug", &used).Error; err != nil {
+		return "", err
+	}
+
+	var oldSlugs []string
+	if err := liveSlugHistory(db, blogID).
+		Where("(blog_slugs.slug = ? OR blog_slugs.slug LIKE ?)", base, pattern).
+		Pluck("blog_slugs.slug", &oldSlugs).Error; err != nil {
+		return "", err
+	}
+
+	taken := make(map[string]bool, len(used)+len(oldSlugs))
+	for _, value := range append(used, oldSlugs...) {
+		taken[value] = true
+	}
+
+	candidate := base
+	for n := 2; taken[candidate] || reservedBlogSlugs[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+	return candidate, nil
+}
+
+// liveSlugHistory selects the old slugs of blogs other than blogID that have
+// not been deleted; those of deleted blogs are free to reuse
+func liveSlugHistory(db *gorm.DB, blogID uint) *gorm.DB {
+	return db.Model(&models.BlogSlug{}).
+		Joins("JOIN blogs ON blogs.id = blog_slugs.blog_id AND blogs.deleted_at IS NULL").
+		Where("blog_slugs.blog_id != ?", blogID)
+}
+
+// reservedBlogSlugs clash with blog routes, or would with likely future ones
+var reservedBlogSlugs = map[string]bool{
+	"slug":       true,
+	"new":        true,
+	"edit":       true,
+	"drafts":     true,
+	"search":     true,
+	"feed":       true,
+	"rss":        true,
+	"me":         true,
+	"admin":      true,
+	"api":        true,
+	"tags":       true,
+	"categories": true,
+}
+
+// checkCustomSlug rejects a user-chosen slug that is reserved, or that another
+// blog uses now or used before. Slugs of deleted blogs may be reused.
+func checkCustomSlug(db *gorm.DB, value string, blogID uint) error {
+	if reservedBlogSlugs[value] {
+		return ErrBlogSlugReserved
+	}
+
+	var count int64
+	if err := db.Model(&models.Blog{}).Where("slug = ? AND id != ?", value, blogID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrBlogSlugTaken
+	}
+
+	if err := liveSlugHistory(db, blogID).Where("blog_slugs.slug = ?", value).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrBlogSlugTaken
+	}
+	return nil
+}
+
+// releaseOldSlug drops the history entry for a slug that is about to become
+// live again, since the live slug always takes precedence
+func releaseOldSlug(tx *gorm.DB, value string) error {
+	return tx.Where("slug = ?", value).Delete(&models.BlogSlug{}).Error
+}
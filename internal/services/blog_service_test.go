@@ -0,0 +1,377 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func newTestBlogService() *BlogService {
+	return &BlogService{cfg: &config.Config{}}
+}
+
+func actorFor(user *models.User) Actor {
+	return Actor{UserID: user.ID, Role: user.Role}
+}
+
+func createTestBlog(t *testing.T, user *models.User, req models.CreateBlogRequest) *models.Blog {
+	t.Helper()
+
+	if req.Content == "" {
+		req.Content = "Content of " + req.Title
+	}
+	blog, err := newTestBlogService().CreateBlog(user.ID, req)
+	if err != nil {
+		t.Fatalf("CreateBlog %q: %v", req.Title, err)
+	}
+	return blog
+}
+
+// setBlogDate backdates a blog as if it was written and published at the given time
+func setBlogDate(t *testing.T, db *gorm.DB, blog *models.Blog, at time.Time) {
+	t.Helper()
+
+	updates := map[string]interface{}{"created_at": at}
+	if blog.Published {
+		updates["published_at"] = at
+	}
+	if err := db.Model(blog).UpdateColumns(updates).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func blogTitles(blogs []models.Blog) []string {
+	titles := make([]string, len(blogs))
+	for i, blog := range blogs {
+		titles[i] = blog.Title
+	}
+	return titles
+}
+
+func listBlogs(t *testing.T, viewer Actor, params models.BlogQueryParams) []string {
+	t.Helper()
+
+	params.Page, params.Limit = 1, 100
+	blogs, total, err := newTestBlogService().GetBlogs(viewer, params)
+	if err != nil {
+		t.Fatalf("GetBlogs(%+v): %v", params, err)
+	}
+	if total != int64(len(blogs)) {
+		t.Errorf("GetBlogs(%+v): total %d, listed %d", params, total, len(blogs))
+	}
+	return blogTitles(blogs)
+}
+
+func TestGetBlogsFiltersAndSorts(t *testing.T) {
+	db := testDB(t)
+	alice := createTestUser(t, db, "alice@example.com")
+	bob := createTestUser(t, db, "bob@example.com")
+
+	day := func(d, hour int) time.Time { return time.Date(2025, 3, d, hour, 0, 0, 0, time.Local) }
+	for _, b := range []struct {
+		user  *models.User
+		title string
+		at    time.Time
+	}{
+		{alice, "Banana", day(1, 9)},
+		{alice, "Apple", day(2, 23)},
+		{bob, "Cherry", day(3, 0)},
+	} {
+		setBlogDate(t, db, createTestBlog(t, b.user, models.CreateBlogRequest{Title: b.title, Published: true}), b.at)
+	}
+
+	tests := []struct {
+		name   string
+		params models.BlogQueryParams
+		want   []string
+	}{
+		{"newest first by default", models.BlogQueryParams{}, []string{"Cherry", "Apple", "Banana"}},
+		{"relevance needs a search", models.BlogQueryParams{Sort: models.BlogSortRelevance}, []string{"Cherry", "Apple", "Banana"}},
+		{"oldest", models.BlogQueryParams{Sort: models.BlogSortOldest}, []string{"Banana", "Apple", "Cherry"}},
+		{"title", models.BlogQueryParams{Sort: models.BlogSortTitle}, []string{"Apple", "Banana", "Cherry"}},
+		{"author", models.BlogQueryParams{UserID: alice.ID}, []string{"Apple", "Banana"}},
+		{"bare to date covers the whole day", models.BlogQueryParams{To: "2025-03-02"}, []string{"Apple", "Banana"}},
+		{"date range", models.BlogQueryParams{From: "2025-03-02", To: "2025-03-02"}, []string{"Apple"}},
+		{"timestamp bound", models.BlogQueryParams{From: day(2, 23).Format(time.RFC3339)}, []string{"Cherry", "Apple"}},
+	}
+
+	for _, tt := range tests {
+		if got := listBlogs(t, Actor{}, tt.params); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, _, err := newTestBlogService().GetBlogs(Actor{}, models.BlogQueryParams{Page: 1, Limit: 10, From: "March"}); !errors.Is(err, ErrInvalidDateRange) {
+		t.Errorf("bad date: GetBlogs = %v, want %v", err, ErrInvalidDateRange)
+	}
+}
+
+func TestGetBlogsSearchRanksTitleMatchesFirst(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "search@example.com")
+
+	createTestBlog(t, user, models.CreateBlogRequest{Title: "Baking bread", Content: "Notes on concurrency while the dough rises", Published: true})
+	createTestBlog(t, user, models.CreateBlogRequest{Title: "Concurrency in Go", Content: "Goroutines and channels", Published: true})
+	createTestBlog(t, user, models.CreateBlogRequest{Title: "Gardening", Content: "Nothing to see here", Published: true})
+
+	blogs, total, err := newTestBlogService().GetBlogs(Actor{}, models.BlogQueryParams{Page: 1, Limit: 10, Search: "concurrency"})
+	if err != nil {
+		t.Fatalf("GetBlogs: %v", err)
+	}
+	if want := []string{"Concurrency in Go", "Baking bread"}; total != 2 || !reflect.DeepEqual(blogTitles(blogs), want) {
+		t.Fatalf("got %v (total %d), want %v", blogTitles(blogs), total, want)
+	}
+	if want := "<mark>concurrency</mark>"; !strings.Contains(blogs[1].Snippet, want) {
+		t.Errorf("snippet %q does not contain %q", blogs[1].Snippet, want)
+	}
+}
+
+func TestGetBlogsSearchSnippetIsEscaped(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "snippet@example.com")
+
+	content := `Beware <script>alert("x")</script> and <img src=x onerror=alert(1)> & 'quotes' in concurrency`
+	createTestBlog(t, user, models.CreateBlogRequest{Title: "Escaping", Content: content, Published: true})
+
+	blogs, _, err := newTestBlogService().GetBlogs(Actor{}, models.BlogQueryParams{Page: 1, Limit: 10, Search: "concurrency"})
+	if err != nil {
+		t.Fatalf("GetBlogs: %v", err)
+	}
+	if len(blogs) != 1 {
+		t.Fatalf("got %v, want the one blog", blogTitles(blogs))
+	}
+
+	// Only the highlight itself may be markup
+	snippet := strings.ReplaceAll(strings.ReplaceAll(blogs[0].Snippet, "<mark>", ""), "</mark>", "")
+	if strings.ContainsAny(snippet, `<>"'`) {
+		t.Errorf("snippet %q contains unescaped HTML", blogs[0].Snippet)
+	}
+	for _, want := range []string{"&lt;script&gt;", "&quot;x&quot;", "&amp;", "&#39;quotes&#39;", "<mark>concurrency</mark>"} {
+		if !strings.Contains(blogs[0].Snippet, want) {
+			t.Errorf("snippet %q does not contain %q", blogs[0].Snippet, want)
+		}
+	}
+}
+
+func TestDraftsAreOnlyVisibleToAuthorAndAdmins(t *testing.T) {
+	db := testDB(t)
+	author := createTestUser(t, db, "author@example.com")
+	other := createTestUser(t, db, "other@example.com")
+	admin := createTestUser(t, db, "admin@example.com")
+	admin.Role = models.RoleAdmin
+
+	createTestBlog(t, author, models.CreateBlogRequest{Title: "Live", Published: true})
+	draft := createTestBlog(t, author, models.CreateBlogRequest{Title: "Draft"})
+	service := newTestBlogService()
+
+	tests := []struct {
+		name    string
+		viewer  Actor
+		visible bool
+	}{
+		{"anonymous", Actor{}, false},
+		{"another author", actorFor(other), false},
+		{"the author", actorFor(author), true},
+		{"an admin", actorFor(admin), true},
+	}
+
+	for _, tt := range tests {
+		want := []string{"Live"}
+		if tt.visible {
+			want = []string{"Draft", "Live"}
+		}
+		if got := listBlogs(t, tt.viewer, models.BlogQueryParams{Sort: models.BlogSortTitle}); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: listed %v, want %v", tt.name, got, want)
+		}
+
+		_, err := service.GetBlogById(tt.viewer, draft.ID)
+		if tt.visible != (err == nil) || (err != nil && !errors.Is(err, ErrBlogNotFound)) {
+			t.Errorf("%s: GetBlogById = %v, visible %v", tt.name, err, tt.visible)
+		}
+		_, _, err = service.GetBlogBySlug(tt.viewer, draft.Slug)
+		if tt.visible != (err == nil) || (err != nil && !errors.Is(err, ErrBlogNotFound)) {
+			t.Errorf("%s: GetBlogBySlug = %v, visible %v", tt.name, err, tt.visible)
+		}
+	}
+
+	// /users/me/blogs lists the caller's own blogs, drafts included
+	if got := listBlogs(t, actorFor(other), models.BlogQueryParams{UserID: other.ID}); len(got) != 0 {
+		t.Errorf("another author's own blogs: %v, want none", got)
+	}
+	if got := listBlogs(t, actorFor(author), models.BlogQueryParams{UserID: author.ID, Sort: models.BlogSortTitle}); !reflect.DeepEqual(got, []string{"Draft", "Live"}) {
+		t.Errorf("author's own blogs: %v, want [Draft Live]", got)
+	}
+}
+
+func TestGetBlogBySlugFollowsOldSlugs(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "history@example.com")
+	service := newTestBlogService()
+
+	blog := createTestBlog(t, user, models.CreateBlogRequest{Title: "First title", Published: true})
+	rename := func(title string) {
+		t.Helper()
+		if _, err := service.UpdateBlog(blog.ID, actorFor(user), models.UpdateBlogRequest{Title: title}); err != nil {
+			t.Fatalf("UpdateBlog %q: %v", title, err)
+		}
+	}
+	lookup := func(value string, wantMoved bool) {
+		t.Helper()
+		found, moved, err := service.GetBlogBySlug(Actor{}, value)
+		if err != nil {
+			t.Fatalf("GetBlogBySlug %q: %v", value, err)
+		}
+		if found.ID != blog.ID || moved != wantMoved {
+			t.Errorf("GetBlogBySlug %q = blog %d, moved %v; want blog %d, moved %v", value, found.ID, moved, blog.ID, wantMoved)
+		}
+	}
+
+	rename("Second title")
+	lookup("second-title", false)
+	lookup("first-title", true)
+
+	// Going back to an old slug makes it live again
+	rename("First title")
+	lookup("first-title", false)
+	lookup("second-title", true)
+
+	if _, _, err := service.GetBlogBySlug(Actor{}, "third-title"); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("unknown slug: GetBlogBySlug = %v, want %v", err, ErrBlogNotFound)
+	}
+}
+
+func TestBlogSlugsAreUnique(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "slugs@example.com")
+	service := newTestBlogService()
+
+	var slugs []string
+	for _, title := range []string{"Same title", "Same title", "Same Title!", "New", "!!!"} {
+		slugs = append(slugs, createTestBlog(t, user, models.CreateBlogRequest{Title: title}).Slug)
+	}
+	if want := []string{"same-title", "same-title-2", "same-title-3", "new-2", "post"}; !reflect.DeepEqual(slugs, want) {
+		t.Errorf("slugs = %v, want %v", slugs, want)
+	}
+
+	// A renamed blog keeps its old slug, so that can't be handed out again
+	moved := createTestBlog(t, user, models.CreateBlogRequest{Title: "Moved"})
+	if _, err := service.UpdateBlog(moved.ID, actorFor(user), models.UpdateBlogRequest{Title: "Moved away"}); err != nil {
+		t.Fatalf("UpdateBlog: %v", err)
+	}
+	if got := createTestBlog(t, user, models.CreateBlogRequest{Title: "Moved"}).Slug; got != "moved-2" {
+		t.Errorf("slug of a renamed blog reused: got %q, want %q", got, "moved-2")
+	}
+
+	tests := []struct {
+		slug string
+		want error
+	}{
+		{"admin", ErrBlogSlugReserved},
+		{"same-title", ErrBlogSlugTaken},
+		{"moved", ErrBlogSlugTaken},
+		{"my-own-slug", nil},
+	}
+	for _, tt := range tests {
+		_, err := service.CreateBlog(user.ID, models.CreateBlogRequest{Title: "Custom", Content: "Custom", Slug: tt.slug})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("custom slug %q: CreateBlog = %v, want %v", tt.slug, err, tt.want)
+		}
+	}
+
+	// A blog may take back its own old slug
+	if updated, err := service.UpdateBlog(moved.ID, actorFor(user), models.UpdateBlogRequest{Slug: "moved"}); err != nil || updated.Slug != "moved" {
+		t.Errorf("taking back own slug: UpdateBlog = %v, %v", updated, err)
+	}
+
+	// Slugs of deleted blogs are free again, current and old alike
+	gone := createTestBlog(t, user, models.CreateBlogRequest{Title: "Gone"})
+	if _, err := service.UpdateBlog(gone.ID, actorFor(user), models.UpdateBlogRequest{Title: "Gone for good"}); err != nil {
+		t.Fatalf("UpdateBlog: %v", err)
+	}
+	if err := service.DeleteBlog(gone.ID, actorFor(user)); err != nil {
+		t.Fatalf("DeleteBlog: %v", err)
+	}
+	if got := createTestBlog(t, user, models.CreateBlogRequest{Title: "Gone"}).Slug; got != "gone" {
+		t.Errorf("old slug of a deleted blog: got %q, want %q", got, "gone")
+	}
+	if got := createTestBlog(t, user, models.CreateBlogRequest{Title: "Gone for good"}).Slug; got != "gone-for-good" {
+		t.Errorf("slug of a deleted blog: got %q, want %q", got, "gone-for-good")
+	}
+}
+
+// beforeCreate runs fn ahead of every insert into table for the rest of the test
+func beforeCreate(t *testing.T, db *gorm.DB, table string, fn func(tx *gorm.DB)) {
+	t.Helper()
+
+	name := "test:before_create_" + table
+	err := db.Callback().Create().Before("gorm:create").Register(name, func(tx *gorm.DB) {
+		if tx.Statement.Table == table {
+			fn(tx)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Callback().Create().Remove(name)
+	})
+}
+
+func TestSaveBlogRetriesLostSlugRaces(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "race@example.com")
+
+	first := createTestBlog(t, user, models.CreateBlogRequest{Title: "!!!"})
+
+	// Another save takes the slug this one picked, right before it is stored
+	stolen := 0
+	beforeCreate(t, db, "blogs", func(tx *gorm.DB) {
+		blog, ok := tx.Statement.Dest.(*models.Blog)
+		if !ok || stolen >= 2 {
+			return
+		}
+		stolen++
+		if err := db.Exec(`INSERT INTO blogs (title, content, slug, user_id, published, created_at, updated_at)
+			VALUES ('Other', 'Other', ?, ?, false, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, blog.Slug, user.ID).Error; err != nil {
+			t.Errorf("taking slug %q: %v", blog.Slug, err)
+		}
+	})
+
+	second := createTestBlog(t, user, models.CreateBlogRequest{Title: "!!!"})
+	if first.Slug != "post" || second.Slug != "post-4" {
+		t.Errorf("untitled blog slugs = %q, %q; want post, post-4", first.Slug, second.Slug)
+	}
+	if stolen != 2 {
+		t.Errorf("the slug was taken %d times, want 2", stolen)
+	}
+}
+
+func TestSaveBlogOtherConflictsAreNotSlugConflicts(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "conflict@example.com")
+
+	// A concurrent edit records the revision number this save wanted
+	beforeCreate(t, db, "blog_revisions", func(tx *gorm.DB) {
+		revision, ok := tx.Statement.Dest.(*models.BlogRevision)
+		if !ok {
+			return
+		}
+		if err := tx.Session(&gorm.Session{NewDB: true}).Exec(`INSERT INTO blog_revisions (blog_id, number, title, content, published, editor_id, created_at)
+			VALUES (?, ?, 'Other', 'Other', false, ?, CURRENT_TIMESTAMP)`, revision.BlogID, revision.Number, user.ID).Error; err != nil {
+			t.Errorf("taking revision %d: %v", revision.Number, err)
+		}
+	})
+
+	_, err := newTestBlogService().CreateBlog(user.ID, models.CreateBlogRequest{Title: "Conflict", Content: "Conflict"})
+	var domainErr *Error
+	if !errors.As(err, &domainErr) || domainErr.Code != ErrDatabase.Code {
+		t.Errorf("CreateBlog = %v, want %v", err, ErrDatabase)
+	}
+}
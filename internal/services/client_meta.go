@@ -0,0 +1,7 @@
+package services
+
+// ClientMeta describes the client a request came from
+type ClientMeta struct {
+	IP        string
+	UserAgent string
+}
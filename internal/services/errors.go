@@ -0,0 +1,157 @@
+package services
+
+import "errors"
+
+// ErrorKind classifies a domain error without tying services to HTTP; the
+// error handler decides which status each kind becomes
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindInvalid
+	KindUnauthenticated
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindRateLimited
+	KindUnavailable
+)
+
+// Error is a domain error with a stable, machine readable code. Message is
+// safe to show to clients; the wrapped cause is not.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors by code, so errors.Is(err, ErrBlogNotFound) holds for a
+// copy made by Wrap as well
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of e that records cause for logging
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.Err = cause
+	return &wrapped
+}
+
+func newError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+var (
+	ErrDatabase = newError(KindInternal, "database_error", "database error")
+	ErrInternal = newError(KindInternal, "internal_error", "an unexpected error occurred")
+
+	ErrEmailRequired       = newError(KindInvalid, "email_required", "email is required")
+	ErrInvalidEmail        = newError(KindInvalid, "invalid_email", "invalid email format")
+	ErrTokenRequired       = newError(KindInvalid, "token_required", "token is required")
+	ErrEmailTaken          = newError(KindConflict, "email_taken", "user with this email already exists")
+	ErrInvalidCredentials  = newError(KindUnauthenticated, "invalid_credentials", "invalid credentials")
+	ErrAccountDeactivated  = newError(KindUnauthenticated, "account_deactivated", "account is deactivated")
+	ErrEmailNotVerified    = newError(KindForbidden, "email_not_verified", "email not verified")
+	ErrTooManyLogins       = newError(KindRateLimited, "too_many_login_attempts", "too many login attempts, please try again later")
+	ErrTooManyRequests     = newError(KindRateLimited, "too_many_requests", "too many requests, please try again later")
+	ErrUserNotFound        = newError(KindNotFound, "user_not_found", "user not found")
+	ErrResetEmailFailed    = newError(KindUnavailable, "email_delivery_failed", "failed to send reset email")
+	ErrVerifyEmailFailed   = newError(KindUnavailable, "email_delivery_failed", "failed to send verification email")
+	ErrSignInEmailFailed   = newError(KindUnavailable, "email_delivery_failed", "failed to send sign-in email")
+	ErrMFACodeRequired     = newError(KindInvalid, "mfa_code_required", "mfa token and code are required")
+	ErrInvalidMFAToken     = newError(KindUnauthenticated, "invalid_mfa_token", "invalid or expired mfa token")
+	ErrInvalidMFACode      = newError(KindUnauthenticated, "invalid_mfa_code", "invalid two-factor code")
+	ErrMFAAlreadyEnabled   = newError(KindConflict, "mfa_already_enabled", "two-factor authentication is already enabled")
+	ErrMFANotEnabled       = newError(KindConflict, "mfa_not_enabled", "two-factor authentication is not enabled")
+	ErrMFASetupNotStarted  = newError(KindConflict, "mfa_setup_not_started", "two-factor authentication setup has not been started")
+	ErrInvalidPassword     = newError(KindUnauthenticated, "invalid_password", "password is incorrect")
+	ErrRefreshRequired     = newError(KindInvalid, "refresh_token_required", "refresh token is required")
+	ErrInvalidRefreshToken = newError(KindUnauthenticated, "invalid_refresh_token", "invalid refresh token")
+	ErrRefreshTokenReused  = newError(KindUnauthenticated, "refresh_token_reused", "refresh token reuse detected")
+	ErrInvalidMagicLink    = newError(KindUnauthenticated, "invalid_magic_link", "invalid or expired sign-in link")
+	ErrInvalidVerifyToken  = newError(KindInvalid, "invalid_verification_token", "invalid or expired verification token")
+	ErrInvalidUnlockToken  = newError(KindInvalid, "invalid_unlock_token", "invalid or expired unlock token")
+	ErrInvalidResetToken   = newError(KindInvalid, "invalid_reset_token", "invalid or expired reset token")
+	ErrResetFieldsRequired = newError(KindInvalid, "reset_fields_required", "token and new password are required")
+
+	ErrAuthHeaderRequired      = newError(KindUnauthenticated, "authorization_required", "authorization header required")
+	ErrInvalidAuthHeader       = newError(KindUnauthenticated, "invalid_authorization_header", "invalid authorization header format")
+	ErrInvalidToken            = newError(KindUnauthenticated, "invalid_token", "invalid token")
+	ErrTokenRevoked            = newError(KindUnauthenticated, "token_revoked", "token has been revoked")
+	ErrSessionRevoked          = newError(KindUnauthenticated, "session_revoked", "session has been revoked")
+	ErrInsufficientPermissions = newError(KindForbidden, "insufficient_permissions", "insufficient permissions")
+	ErrSessionAuthRequired     = newError(KindForbidden, "session_auth_required", "this endpoint cannot be used with an API key")
+	ErrSessionNotFound         = newError(KindNotFound, "session_not_found", "session not found")
+
+	ErrPasswordFieldsRequired    = newError(KindInvalid, "password_fields_required", "current and new password are required")
+	ErrInvalidBlogAction         = newError(KindInvalid, "invalid_blog_action", "blog_action must be one of transfer, anonymize or cascade")
+	ErrTransferEmailRequired     = newError(KindInvalid, "transfer_email_required", "transfer_to_email is required to transfer blogs")
+	ErrTransferToSelf            = newError(KindInvalid, "transfer_to_self", "cannot transfer blogs to yourself")
+	ErrTransferRecipientNotFound = newError(KindNotFound, "transfer_recipient_not_found", "transfer recipient not found")
+	ErrRecentSignInRequired      = newError(KindForbidden, "recent_sign_in_required", "sign in again to confirm, accounts without a password need a sign-in from the last 10 minutes")
+
+	ErrDeactivateSelf = newError(KindInvalid, "cannot_deactivate_self", "you cannot deactivate your own account")
+	ErrChangeOwnRole  = newError(KindInvalid, "cannot_change_own_role", "you cannot change your own role")
+	ErrInvalidRole    = newError(KindInvalid, "invalid_role", "invalid role")
+
+	ErrAPIKeyNameRequired  = newError(KindInvalid, "api_key_name_required", "name is required")
+	ErrAPIKeyNameTooLong   = newError(KindInvalid, "api_key_name_too_long", "name must be at most 100 characters")
+	ErrAPIKeyScopeRequired = newError(KindInvalid, "api_key_scope_required", "at least one scope is required")
+	ErrInvalidAPIKeyScope  = newError(KindInvalid, "invalid_api_key_scope", "scopes must be permissions your role grants")
+	ErrInvalidAPIKeyExpiry = newError(KindInvalid, "invalid_api_key_expiry", "expires_in_days must be between 1 and 365")
+	ErrAPIKeyNotFound      = newError(KindNotFound, "api_key_not_found", "api key not found")
+	ErrInvalidAPIKey       = newError(KindUnauthenticated, "invalid_api_key", "invalid api key")
+
+	ErrUnknownOIDCProvider     = newError(KindNotFound, "unknown_oidc_provider", "unknown identity provider")
+	ErrOIDCCallbackRequired    = newError(KindInvalid, "oidc_callback_required", "code and state are required")
+	ErrInvalidOIDCState        = newError(KindUnauthenticated, "invalid_oidc_state", "invalid or expired state")
+	ErrOIDCAuthFailed          = newError(KindUnauthenticated, "oidc_authentication_failed", "identity provider authentication failed")
+	ErrOIDCProviderUnavailable = newError(KindUnavailable, "oidc_provider_unavailable", "identity provider unavailable")
+	ErrOIDCEmailNotVerified    = newError(KindForbidden, "oidc_email_not_verified", "email not verified by identity provider")
+	ErrOIDCAccountUnverified   = newError(KindConflict, "oidc_account_unverified", "an account with this email exists but its email has not been verified, verify it before signing in with a provider")
+	ErrIdentityLinkedElsewhere = newError(KindConflict, "identity_linked_elsewhere", "identity is already linked to another account")
+	ErrProviderAlreadyLinked   = newError(KindConflict, "provider_already_linked", "provider is already linked")
+	ErrProviderNotLinked       = newError(KindNotFound, "provider_not_linked", "provider is not linked")
+	ErrLastSignInMethod        = newError(KindConflict, "last_sign_in_method", "cannot unlink the only sign-in method, set a password first")
+
+	ErrBlogNotFound           = newError(KindNotFound, "blog_not_found", "blog not found")
+	ErrBlogUpdateForbidden    = newError(KindForbidden, "blog_update_forbidden", "unauthorized to update this blog")
+	ErrBlogPublishForbidden   = newError(KindForbidden, "blog_publish_forbidden", "unauthorized to publish this blog")
+	ErrBlogDeleteForbidden    = newError(KindForbidden, "blog_delete_forbidden", "unauthorized to delete this blog")
+	ErrBlogSlugTaken          = newError(KindConflict, "blog_slug_taken", "slug already in use")
+	ErrBlogSlugReserved       = newError(KindInvalid, "blog_slug_reserved", "slug is reserved")
+	ErrBlogRevisionNotFound   = newError(KindNotFound, "blog_revision_not_found", "blog revision not found")
+	ErrBlogRevisionsForbidden = newError(KindForbidden, "blog_revisions_forbidden", "unauthorized to view revisions of this blog")
+	ErrInvalidSchedule        = newError(KindInvalid, "invalid_schedule", "unpublish_at must be in the future and after publish_at")
+	ErrImageUploadFailed      = newError(KindUnavailable, "image_upload_failed", "failed to upload image")
+	ErrInvalidDateRange       = newError(KindInvalid, "invalid_date_range", "from and to must be dates (YYYY-MM-DD) or RFC 3339 timestamps")
+
+	ErrTagNotFound       = newError(KindNotFound, "tag_not_found", "tag not found")
+	ErrTagSlugTaken      = newError(KindConflict, "tag_slug_taken", "another tag already uses this slug, merge the tags instead")
+	ErrTagMergeSelf      = newError(KindInvalid, "tag_merge_self", "a tag cannot be merged into itself")
+	ErrInvalidTag        = newError(KindInvalid, "invalid_tag", "tags must be at most 64 characters and contain a letter or digit")
+	ErrCategoryNotFound  = newError(KindNotFound, "category_not_found", "category not found")
+	ErrCategorySlugTaken = newError(KindConflict, "category_slug_taken", "another category already uses this slug")
+	ErrCategoryCycle     = newError(KindInvalid, "category_cycle", "a category cannot be placed under itself or one of its subcategories")
+	ErrInvalidCategory   = newError(KindInvalid, "invalid_category", "category names must contain a letter or digit")
+	ErrUnknownCategory   = newError(KindInvalid, "unknown_category", "category does not exist")
+
+	ErrSampleNotFound  = newError(KindNotFound, "sample_not_found", "sample not found")
+	ErrSampleForbidden = newError(KindForbidden, "sample_forbidden", "you can only modify your own samples")
+)
+
+// Internal signals between services, never returned to controllers
+var (
+	errInvalidOneTimeToken = errors.New("invalid token")
+)
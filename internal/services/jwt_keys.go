@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/utils"
+)
+
+var jwtKeySet *utils.KeySet
+
+// LoadJWTKeys loads the access token keyset from cfg.JWTKeysDir. Only in
+// development a missing directory falls back to a throwaway key.
+func LoadJWTKeys(cfg *config.Config) error {
+	if cfg.JWTKeysDir == "" {
+		if !cfg.IsDevelopment() {
+			return errors.New("JWT_KEYS_DIR must be set outside development")
+		}
+
+		keySet, err := utils.NewEphemeralKeySet()
+		if err != nil {
+			return err
+		}
+		log.Println("JWT_KEYS_DIR not set, signing access tokens with an ephemeral development key")
+		jwtKeySet = keySet
+		return nil
+	}
+
+	keySet, err := utils.LoadKeySet(cfg.JWTKeysDir, cfg.JWTSigningKeyID)
+	if err != nil {
+		return err
+	}
+	jwtKeySet = keySet
+	return nil
+}
+
+// JWTConfig returns the settings used to sign and verify access tokens
+func JWTConfig(cfg *config.Config) utils.JWTConfig {
+	return utils.JWTConfig{
+		Keys:     jwtKeySet,
+		Issuer:   cfg.JWTIssuer,
+		Audience: cfg.JWTAudience,
+	}
+}
+
+// JWKS returns the public verification keys
+func JWKS() utils.JWKSet {
+	return jwtKeySet.JWKS()
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// defaultTestDSN points at the postgres_test service of docker-compose.yml
+const defaultTestDSN = "host=localhost port=5433 user=postgres password=admin dbname=go_fiber_test_db sslmode=disable connect_timeout=3"
+
+var (
+	testDBOnce sync.Once
+	testDBConn *gorm.DB
+	testDBErr  error
+)
+
+// testDB connects the services to the test database and empties it. The DSN
+// comes from TEST_DATABASE_URL, by default the postgres_test container.
+// Tests that need a database are skipped when it cannot be reached.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	testDBOnce.Do(func() {
+		dsn := os.Getenv("TEST_DATABASE_URL")
+		if dsn == "" {
+			dsn = defaultTestDSN
+		}
+
+		testDBConn, testDBErr = gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger:         logger.Default.LogMode(logger.Silent),
+			TranslateError: true,
+		})
+		if testDBErr == nil {
+			testDBErr = database.Migrate(testDBConn)
+		}
+	})
+	if testDBErr != nil {
+		t.Skipf("test database not available: %v", testDBErr)
+	}
+
+	var tables []string
+	if err := testDBConn.Raw(`SELECT tablename FROM pg_tables WHERE schemaname = current_schema()`).Scan(&tables).Error; err != nil {
+		t.Fatal(err)
+	}
+	for i, table := range tables {
+		tables[i] = `"` + table + `"`
+	}
+	if err := testDBConn.Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY CASCADE").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	database.DB = testDBConn
+	return testDBConn
+}
+
+// createTestUser stores an active author with the given email
+func createTestUser(t *testing.T, db *gorm.DB, email string) *models.User {
+	t.Helper()
+
+	user := &models.User{
+		Email:     email,
+		Password:  "not-a-real-hash",
+		FirstName: "Test",
+		LastName:  strings.Split(email, "@")[0],
+		IsActive:  true,
+		Role:      models.RoleAuthor,
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(fmt.Errorf("create user %s: %w", email, err))
+	}
+	return user
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+var totpCodePattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+type MFAService struct {
+	cfg *config.Config
+	now func() time.Time
+}
+
+func NewMFAService(cfg *config.Config) *MFAService {
+	return &MFAService{cfg: cfg, now: time.Now}
+}
+
+// SetupTOTP generates a new secret for the user. Two-factor authentication is
+// only switched on once ConfirmTOTP proves the authenticator app works.
+func (s *MFAService) SetupTOTP(userID uint) (*models.TOTPSetupResponse, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, ErrInternal.Wrap(err)
+	}
+
+	if err := database.GetDB().Model(user).Updates(map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	}).Error; err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	return &models.TOTPSetupResponse{
+		Secret: secret,
+		URI:    utils.TOTPProvisioningURI(s.cfg.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *MFAService) ConfirmTOTP(userID uint, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, ErrMFASetupNotStarted
+	}
+
+	if err := s.verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("mfa_enabled", true).Error; err != nil {
+			return err
+		}
+
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *MFAService) DisableTOTP(userID uint, password, code string) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+
+	if !utils.CheckPassword(password, user.Password) {
+		return ErrInvalidPassword
+	}
+
+	if err := s.VerifyCode(user, code); err != nil {
+		return err
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"mfa_enabled":    false,
+			"totp_secret":    "",
+			"totp_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes invalidates every existing recovery code. A TOTP
+// code is required so a leaked recovery code cannot mint new ones.
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MFAEnabled {
+		return nil, ErrMFANotEnabled
+	}
+
+	if err := s.verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// VerifyCode accepts either a current TOTP code or an unused recovery code
+func (s *MFAService) VerifyCode(user *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if totpCodePattern.MatchString(code) {
+		return s.verifyTOTP(user, code)
+	}
+	return s.useRecoveryCode(user, code)
+}
+
+func (s *MFAService) verifyTOTP(user *models.User, code string) error {
+	step, ok := utils.ValidateTOTPCode(user.TOTPSecret, strings.TrimSpace(code), s.now(), 1)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	// Each code is accepted at most once, even within its validity window
+	result := database.GetDB().Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return ErrDatabase.Wrap(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+
+	user.TOTPLastStep = step
+	return nil
+}
+
+func (s *MFAService) useRecoveryCode(user *models.User, code string) error {
+	result := database.GetDB().Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, utils.HashToken(utils.NormalizeRecoveryCode(code))).
+		Update("used_at", s.now())
+	if result.Error != nil {
+		return ErrDatabase.Wrap(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+func (s *MFAService) replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes := make([]models.RecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		recoveryCodes = append(recoveryCodes, models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(code),
+		})
+	}
+
+	if err := tx.Create(&recoveryCodes).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *MFAService) findUser(userID uint) (*models.User, error) {
+	var user models.User
+	if err := database.GetDB().First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return &user, nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+
+	"gorm.io/gorm"
+)
+
+// mfaTestTime sits in the middle of a 30 second TOTP step
+var mfaTestTime = time.Date(2025, 1, 15, 10, 30, 15, 0, time.UTC)
+
+var recoveryCodePattern = regexp.MustCompile(`^[0-9a-f]{5}(-[0-9a-f]{5}){3}$`)
+
+func newTestMFAService(now time.Time) *MFAService {
+	service := NewMFAService(&config.Config{TOTPIssuer: "Blog App"})
+	service.now = func() time.Time { return now }
+	return service
+}
+
+// setupTOTPUser creates a user who has started, but not confirmed, TOTP setup
+func setupTOTPUser(t *testing.T, db *gorm.DB, service *MFAService, email string) *models.User {
+	t.Helper()
+
+	user := createTestUser(t, db, email)
+	if _, err := service.SetupTOTP(user.ID); err != nil {
+		t.Fatalf("SetupTOTP: %v", err)
+	}
+	if err := db.First(user, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func totpCodeAt(t *testing.T, user *models.User, at time.Time) string {
+	t.Helper()
+
+	code, err := utils.GenerateTOTPCode(user.TOTPSecret, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestVerifyCodeAcceptsOneStepOfSkew(t *testing.T) {
+	db := testDB(t)
+	service := newTestMFAService(mfaTestTime)
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   error
+	}{
+		{"two steps behind", -60 * time.Second, ErrInvalidMFACode},
+		{"one step behind", -30 * time.Second, nil},
+		{"current step", 0, nil},
+		{"one step ahead", 30 * time.Second, nil},
+		{"two steps ahead", 60 * time.Second, ErrInvalidMFACode},
+	}
+
+	for i, tt := range tests {
+		user := setupTOTPUser(t, db, service, string(rune('a'+i))+"@example.com")
+
+		err := service.VerifyCode(user, totpCodeAt(t, user, mfaTestTime.Add(tt.offset)))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: VerifyCode = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyCodeRejectsReplayedStep(t *testing.T) {
+	db := testDB(t)
+	service := newTestMFAService(mfaTestTime)
+	user := setupTOTPUser(t, db, service, "replay@example.com")
+
+	code := totpCodeAt(t, user, mfaTestTime)
+	if err := service.VerifyCode(user, code); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+
+	// A fresh copy of the user, as a second login request would load it
+	var reloaded models.User
+	if err := db.First(&reloaded, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := service.VerifyCode(&reloaded, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("replayed code: VerifyCode = %v, want %v", err, ErrInvalidMFACode)
+	}
+
+	// The previous step is inside the window but older than the one just used
+	if err := service.VerifyCode(&reloaded, totpCodeAt(t, user, mfaTestTime.Add(-30*time.Second))); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("older step: VerifyCode = %v, want %v", err, ErrInvalidMFACode)
+	}
+
+	if err := service.VerifyCode(&reloaded, totpCodeAt(t, user, mfaTestTime.Add(30*time.Second))); err != nil {
+		t.Errorf("next step: VerifyCode = %v, want nil", err)
+	}
+}
+
+func TestRecoveryCodesAreSingleUse(t *testing.T) {
+	db := testDB(t)
+	service := newTestMFAService(mfaTestTime)
+	user := setupTOTPUser(t, db, service, "recovery@example.com")
+
+	result, err := service.ConfirmTOTP(user.ID, totpCodeAt(t, user, mfaTestTime))
+	if err != nil {
+		t.Fatalf("ConfirmTOTP: %v", err)
+	}
+	codes := result.RecoveryCodes
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+	for _, code := range codes {
+		if !recoveryCodePattern.MatchString(code) {
+			t.Fatalf("recovery code %q does not have 80 bits in groups of five", code)
+		}
+	}
+
+	if err := service.VerifyCode(user, codes[0]); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := service.VerifyCode(user, codes[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("second use: VerifyCode = %v, want %v", err, ErrInvalidMFACode)
+	}
+
+	// Codes may be typed without dashes and in upper case
+	if err := service.VerifyCode(user, strings.ToUpper(strings.ReplaceAll(codes[1], "-", ""))); err != nil {
+		t.Errorf("code typed without dashes: %v", err)
+	}
+
+	// Regenerating invalidates every code that is left
+	if _, err := service.RegenerateRecoveryCodes(user.ID, totpCodeAt(t, user, mfaTestTime.Add(30*time.Second))); err != nil {
+		t.Fatalf("RegenerateRecoveryCodes: %v", err)
+	}
+	if err := service.VerifyCode(user, codes[2]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("code from before regeneration: VerifyCode = %v, want %v", err, ErrInvalidMFACode)
+	}
+}
+
+func TestMFAStateErrors(t *testing.T) {
+	db := testDB(t)
+	service := newTestMFAService(mfaTestTime)
+
+	user := createTestUser(t, db, "state@example.com")
+	if _, err := service.ConfirmTOTP(user.ID, "123456"); !errors.Is(err, ErrMFASetupNotStarted) {
+		t.Errorf("confirm before setup: %v, want %v", err, ErrMFASetupNotStarted)
+	}
+	if err := service.DisableTOTP(user.ID, "password", "123456"); !errors.Is(err, ErrMFANotEnabled) {
+		t.Errorf("disable while off: %v, want %v", err, ErrMFANotEnabled)
+	}
+
+	user = setupTOTPUser(t, db, service, "enabled@example.com")
+	if _, err := service.ConfirmTOTP(user.ID, totpCodeAt(t, user, mfaTestTime)); err != nil {
+		t.Fatalf("ConfirmTOTP: %v", err)
+	}
+	if _, err := service.SetupTOTP(user.ID); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Errorf("setup while on: %v, want %v", err, ErrMFAAlreadyEnabled)
+	}
+	if err := service.DisableTOTP(user.ID, "wrong password", totpCodeAt(t, user, mfaTestTime)); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("disable with wrong password: %v, want %v", err, ErrInvalidPassword)
+	}
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const oidcAuthRequestTTL = 10 * time.Minute
+
+type OIDCService struct {
+	cfg         *config.Config
+	authService *AuthService
+	clients     map[string]*oidcClient
+}
+
+func NewOIDCService(cfg *config.Config) *OIDCService {
+	return NewOIDCServiceWithClient(cfg, &http.Client{Timeout: 10 * time.Second})
+}
+
+// NewOIDCServiceWithClient lets callers supply the HTTP client used for
+// discovery, JWKS and token requests, e.g. to point at a mock provider
+func NewOIDCServiceWithClient(cfg *config.Config, httpClient *http.Client) *OIDCService {
+	clients := make(map[string]*oidcClient, len(cfg.OIDCProviders))
+	for name, provider := range cfg.OIDCProviders {
+		clients[name] = newOIDCClient(provider, httpClient)
+	}
+
+	return &OIDCService{
+		cfg:         cfg,
+		authService: NewAuthService(cfg),
+		clients:     clients,
+	}
+}
+
+// Providers returns the names of the configured providers
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.clients))
+	for name := range s.clients {
+		names = append(names, name)
+	}
+	return names
+}
+
+// StartLogin begins an authorization code + PKCE flow for signing in
+func (s *OIDCService) StartLogin(ctx context.Context, provider string) (*models.OIDCAuthorizationResponse, error) {
+	return s.startAuthorization(ctx, provider, nil)
+}
+
+// StartLink begins a flow whose result is linked to the given user
+func (s *OIDCService) StartLink(ctx context.Context, provider string, userID uint) (*models.OIDCAuthorizationResponse, error) {
+	return s.startAuthorization(ctx, provider, &userID)
+}
+
+func (s *OIDCService) startAuthorization(ctx context.Context, provider string, linkUserID *uint) (*models.OIDCAuthorizationResponse, error) {
+	client, ok := s.clients[provider]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, ErrInternal.Wrap(err)
+	}
+	nonce, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, ErrInternal.Wrap(err)
+	}
+	codeVerifier, err := utils.GeneratePKCEVerifier()
+	if err != nil {
+		return nil, ErrInternal.Wrap(err)
+	}
+
+	authURL, err := client.AuthorizationURL(ctx, state, nonce, utils.PKCEChallengeS256(codeVerifier))
+	if err != nil {
+		return nil, ErrOIDCProviderUnavailable.Wrap(err)
+	}
+
+	authRequest := models.OIDCAuthRequest{
+		StateHash:    utils.HashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(oidcAuthRequestTTL),
+	}
+
+	db := database.GetDB()
+	db.Where("expires_at < ?", time.Now()).Delete(&models.OIDCAuthRequest{})
+	if err := db.Create(&authRequest).Error; err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	return &models.OIDCAuthorizationResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+	}, nil
+}
+
+// CompleteLogin finishes a sign-in flow. The identity is looked up by its
+// provider subject; failing that it is linked to the account with the same
+// verified email, or a new account is created.
+func (s *OIDCService) CompleteLogin(ctx context.Context, provider string, req models.OIDCCallbackRequest, meta ClientMeta) (*models.LoginResponse, error) {
+	authRequest, claims, err := s.completeAuthorization(ctx, provider, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if authRequest.LinkUserID != nil {
+		return nil, ErrInvalidOIDCState
+	}
+
+	user, err := s.findOrCreateUser(provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authService.completeLogin(user, meta)
+}
+
+// CompleteLink finishes a link flow started by userID
+func (s *OIDCService) CompleteLink(ctx context.Context, provider string, userID uint, req models.OIDCCallbackRequest) (*models.UserIdentity, error) {
+	authRequest, claims, err := s.completeAuthorization(ctx, provider, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if authRequest.LinkUserID == nil || *authRequest.LinkUserID != userID {
+		return nil, ErrInvalidOIDCState
+	}
+
+	var identity models.UserIdentity
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var existing models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, claims.Subject).First(&existing).Error
+		if err == nil {
+			if existing.UserID != userID {
+				return ErrIdentityLinkedElsewhere
+			}
+			identity = existing
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.UserIdentity{}).Where("user_id = ? AND provider = ?", userID, provider).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrProviderAlreadyLinked
+		}
+
+		identity = models.UserIdentity{
+			UserID:   userID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    claims.Email,
+		}
+		return tx.Create(&identity).Error
+	})
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	return &identity, nil
+}
+
+func (s *OIDCService) GetIdentities(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	if err := database.GetDB().Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error; err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return identities, nil
+}
+
+// Unlink removes a linked provider, unless it is the only way left to sign in
+func (s *OIDCService) Unlink(userID uint, provider string) error {
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		var identities []models.UserIdentity
+		if err := tx.Where("user_id = ?", userID).Find(&identities).Error; err != nil {
+			return err
+		}
+
+		var target *models.UserIdentity
+		for i := range identities {
+			if identities[i].Provider == provider {
+				target = &identities[i]
+			}
+		}
+		if target == nil {
+			return ErrProviderNotLinked
+		}
+
+		if user.Password == "" && len(identities) == 1 {
+			return ErrLastSignInMethod
+		}
+
+		return tx.Delete(target).Error
+	})
+	return databaseError(err)
+}
+
+// completeAuthorization consumes the stored state and exchanges the code
+func (s *OIDCService) completeAuthorization(ctx context.Context, provider string, req models.OIDCCallbackRequest) (*models.OIDCAuthRequest, *IDTokenClaims, error) {
+	client, ok := s.clients[provider]
+	if !ok {
+		return nil, nil, ErrUnknownOIDCProvider
+	}
+
+	if req.Code == "" || req.State == "" {
+		return nil, nil, ErrOIDCCallbackRequired
+	}
+
+	var authRequest models.OIDCAuthRequest
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("state_hash = ? AND provider = ?", utils.HashToken(req.State), provider).
+			First(&authRequest).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&authRequest).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, ErrInvalidOIDCState
+		}
+		return nil, nil, ErrDatabase.Wrap(err)
+	}
+
+	if time.Now().After(authRequest.ExpiresAt) {
+		return nil, nil, ErrInvalidOIDCState
+	}
+
+	claims, err := client.Exchange(ctx, req.Code, authRequest.CodeVerifier, authRequest.Nonce)
+	if err != nil {
+		log.Printf("OIDC code exchange with %s failed: %v", provider, err)
+		return nil, nil, ErrOIDCAuthFailed.Wrap(err)
+	}
+
+	return &authRequest, claims, nil
+}
+
+func (s *OIDCService) findOrCreateUser(provider string, claims *IDTokenClaims) (*models.User, error) {
+	var user models.User
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var identity models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, claims.Subject).First(&identity).Error
+		if err == nil {
+			return tx.First(&user, identity.UserID).Error
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		// Only an address the provider vouches for may claim an account
+		email := strings.ToLower(strings.TrimSpace(claims.Email))
+		if email == "" || !bool(claims.EmailVerified) {
+			return ErrOIDCEmailNotVerified
+		}
+
+		now := time.Now()
+		err = tx.Where("LOWER(email) = ?", email).First(&user).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			firstName, lastName := oidcNames(claims)
+			user = models.User{
+				Email:           email,
+				FirstName:       firstName,
+				LastName:        lastName,
+				IsActive:        true,
+				Role:            models.RoleAuthor,
+				EmailVerifiedAt: &now,
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case user.EmailVerifiedAt == nil:
+			// Anyone can register an address they do not own. Linking here
+			// would verify the account and with it the password its
+			// registrant chose, so the owner has to verify the email first.
+			return ErrOIDCAccountUnverified
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    email,
+		}).Error
+	})
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	return &user, nil
+}
+
+// databaseError passes domain errors through and wraps anything else, which
+// can only have come from the database, as ErrDatabase
+func databaseError(err error) error {
+	var domainErr *Error
+	if err == nil || errors.As(err, &domainErr) {
+		return err
+	}
+	return ErrDatabase.Wrap(err)
+}
+
+// oidcNames picks first and last name from the ID token claims
+func oidcNames(claims *IDTokenClaims) (string, string) {
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && claims.Name != "" {
+		parts := strings.SplitN(claims.Name, " ", 2)
+		firstName = parts[0]
+		if len(parts) > 1 && lastName == "" {
+			lastName = parts[1]
+		}
+	}
+	if firstName == "" {
+		firstName = strings.Split(claims.Email, "@")[0]
+	}
+	return firstName, lastName
+}
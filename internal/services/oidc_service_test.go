@@ -0,0 +1,442 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	mockProvider = "mock"
+	mockClientID = "blog-app"
+)
+
+// mockOIDCProvider serves discovery, JWKS and a token endpoint that hands
+// out an ID token for each code registered with authorize
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    ed25519.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockGrant
+	next  int
+}
+
+type mockGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := utils.NewJWK("mock-key", publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &mockOIDCProvider{key: privateKey, codes: make(map[string]mockGrant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JWKSURI:               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(utils.JWKSet{Keys: []utils.JWK{jwk}})
+	})
+	mux.HandleFunc("/token", p.token)
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	grant, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	if !ok || r.PostForm.Get("client_id") != mockClientID ||
+		utils.PKCEChallengeS256(r.PostForm.Get("code_verifier")) != grant.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, grant.claims)
+	token.Header["kid"] = "mock-key"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(oidcTokenResponse{
+		AccessToken: "mock-access-token",
+		TokenType:   "Bearer",
+		IDToken:     idToken,
+	})
+}
+
+// authorize plays the user approving the request behind authURL. The ID
+// token carries the request's nonce unless claims set one, and the returned
+// callback holds the state from authURL.
+func (p *mockOIDCProvider) authorize(t *testing.T, auth *models.OIDCAuthorizationResponse, claims jwt.MapClaims) models.OIDCCallbackRequest {
+	t.Helper()
+
+	authURL, err := url.Parse(auth.AuthorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := authURL.Query()
+
+	now := time.Now()
+	idClaims := jwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   mockClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for name, value := range claims {
+		idClaims[name] = value
+	}
+
+	p.mu.Lock()
+	p.next++
+	code := fmt.Sprintf("code-%d", p.next)
+	p.codes[code] = mockGrant{challenge: query.Get("code_challenge"), claims: idClaims}
+	p.mu.Unlock()
+
+	return models.OIDCCallbackRequest{Code: code, State: query.Get("state")}
+}
+
+func newTestOIDCService(t *testing.T, provider *mockOIDCProvider) *OIDCService {
+	t.Helper()
+
+	cfg := &config.Config{
+		AppEnv:          "development",
+		JWTSecret:       "test-secret",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+		OIDCProviders: map[string]config.OIDCProviderConfig{
+			mockProvider: {
+				Name:        mockProvider,
+				IssuerURL:   provider.server.URL,
+				ClientID:    mockClientID,
+				RedirectURL: "http://localhost:3000/oidc/callback",
+				Scopes:      []string{"openid", "email", "profile"},
+			},
+		},
+	}
+	if err := LoadJWTKeys(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return NewOIDCServiceWithClient(cfg, provider.server.Client())
+}
+
+func verifiedClaims(subject, email string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":            subject,
+		"email":          email,
+		"email_verified": true,
+		"given_name":     "Ada",
+		"family_name":    "Lovelace",
+	}
+}
+
+func startOIDCLogin(t *testing.T, service *OIDCService) *models.OIDCAuthorizationResponse {
+	t.Helper()
+
+	auth, err := service.StartLogin(context.Background(), mockProvider)
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+	return auth
+}
+
+func countIdentities(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+
+	var count int64
+	if err := db.Model(&models.UserIdentity{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestCompleteLoginCreatesAndReusesUser(t *testing.T) {
+	db := testDB(t)
+	provider := newMockOIDCProvider(t)
+	service := newTestOIDCService(t, provider)
+	ctx := context.Background()
+
+	callback := provider.authorize(t, startOIDCLogin(t, service), verifiedClaims("subject-1", "Ada@Example.com"))
+	result, err := service.CompleteLogin(ctx, mockProvider, callback, ClientMeta{})
+	if err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+	if result.Token == "" || result.User.Email != "ada@example.com" {
+		t.Fatalf("first login returned %+v", result)
+	}
+
+	var user models.User
+	if err := db.First(&user, result.User.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Password != "" || !user.IsEmailVerified() || user.FirstName != "Ada" {
+		t.Errorf("created user %+v, want passwordless, verified and named from the claims", user)
+	}
+
+	callback = provider.authorize(t, startOIDCLogin(t, service), verifiedClaims("subject-1", "ada@example.com"))
+	again, err := service.CompleteLogin(ctx, mockProvider, callback, ClientMeta{})
+	if err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+	if again.User.ID != user.ID {
+		t.Errorf("second login signed in user %d, want %d", again.User.ID, user.ID)
+	}
+	if n := countIdentities(t, db); n != 1 {
+		t.Errorf("%d identities stored, want 1", n)
+	}
+}
+
+func TestCompleteLoginLinksVerifiedAccount(t *testing.T) {
+	db := testDB(t)
+	provider := newMockOIDCProvider(t)
+	service := newTestOIDCService(t, provider)
+
+	user := createTestUser(t, db, "verified@example.com")
+	if err := db.Model(user).Update("email_verified_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	callback := provider.authorize(t, startOIDCLogin(t, service), verifiedClaims("subject-2", "verified@example.com"))
+	result, err := service.CompleteLogin(context.Background(), mockProvider, callback, ClientMeta{})
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if result.User.ID != user.ID {
+		t.Errorf("signed in user %d, want existing user %d", result.User.ID, user.ID)
+	}
+}
+
+func TestCompleteLoginRefusesUnverifiedAccount(t *testing.T) {
+	db := testDB(t)
+	provider := newMockOIDCProvider(t)
+	service := newTestOIDCService(t, provider)
+
+	// Registered by someone who never proved they own the address
+	squatter := createTestUser(t, db, "victim@example.com")
+
+	callback := provider.authorize(t, startOIDCLogin(t, service), verifiedClaims("subject-3", "victim@example.com"))
+	if _, err := service.CompleteLogin(context.Background(), mockProvider, callback, ClientMeta{}); !errors.Is(err, ErrOIDCAccountUnverified) {
+		t.Fatalf("CompleteLogin = %v, want %v", err, ErrOIDCAccountUnverified)
+	}
+
+	var user models.User
+	if err := db.First(&user, squatter.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.IsEmailVerified() {
+		t.Error("unverified account was marked verified")
+	}
+	if n := countIdentities(t, db); n != 0 {
+		t.Errorf("%d identities stored, want 0", n)
+	}
+}
+
+func TestCompleteLoginRejectsUnverifiedEmail(t *testing.T) {
+	db := testDB(t)
+	provider := newMockOIDCProvider(t)
+	service := newTestOIDCService(t, provider)
+
+	for _, verified := range []interface{}{false, "false"} {
+		claims := verifiedClaims("subject-4", "unverified@example.com")
+		claims["email_verified"] = verified
+
+		callback := provider.authorize(t, startOIDCLogin(t, service), claims)
+		if _, err := service.CompleteLogin(context.Background(), mockProvider, callback, ClientMeta{}); !errors.Is(err, ErrOIDCEmailNotVerified) {
+			t.Errorf("email_verified=%v: CompleteLogin = %v, want %v", verified, err, ErrOIDCEmailNotVerified)
+		}
+	}
+
+	var users int64
+	if err := db.Model(&models.User{}).Count(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if users != 0 {
+		t.Errorf("%d users created, want 0", users)
+	}
+}
+
+func TestCompleteLoginRejectsBadNonce(t *testing.T) {
+	testDB(t)
+	provider := newMockOIDCProvider(t)
+	service := newTestOIDCService(t, provider)
+
+	claims := verifiedClaims("subject-5", "nonce@example.com")
+	claims["nonce"] = "not-the-nonce-we-sent"
+
+	callback := provider.authorize(t, startOIDCLogin(t, service), claims)
+	if _, err := service.CompleteLogin(context.Background(), mockProvider, callback, ClientMeta{}); !errors.Is(err, ErrOIDCAuthFailed) {
+		t.Fatalf("CompleteLogin = %v, want %v", err, ErrOIDCAuthFailed)
+	}
+}
+
+func TestCompleteLoginRejectsReusedOrExpiredState(t *testing.T) {
+	db := testDB(t)
+	provider := newMockOIDCProvider(t)
+	service := newTestOIDCService(t, provider)
+	ctx := context.Background()
+
+	auth := startOIDCLogin(t, service)
+	callback := provider.authorize(t, auth, verifiedClaims("subject-6", "state@example.com"))
+	if _, err := service.CompleteLogin(ctx, mockProvider, callback, ClientMeta{}); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+
+	// Replaying the state, even with a fresh code, must not sign in again
+	replay := provider.authorize(t, auth, verifiedClaims("subject-6", "state@example.com"))
+	if _, err := service.CompleteLogin(ctx, mockProvider, replay, ClientMeta{}); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("replayed state: CompleteLogin = %v, want %v", err, ErrInvalidOIDCState)
+	}
+
+	expired := startOIDCLogin(t, service)
+	if err := db.Model(&models.OIDCAuthRequest{}).
+		Where("state_hash = ?", utils.HashToken(expired.State)).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	callback = provider.authorize(t, expired, verifiedClaims("subject-6", "state@example.com"))
+	if _, err := service.CompleteLogin(ctx, mockProvider, callback, ClientMeta{}); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("expired state: CompleteLogin = %v, want %v", err, ErrInvalidOIDCState)
+	}
+
+	callback = provider.authorize(t, startOIDCLogin(t, service), verifiedClaims("subject-6", "state@example.com"))
+	callback.State = "made-up-state"
+	if _, err := service.CompleteLogin(ctx, mockProvider, callback, ClientMeta{}); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("unknown state: CompleteLogin = %v, want %v", err, ErrInvalidOIDCState)
+	}
+}
+
+func TestCompleteLink(t *testing.T) {
+	db := testDB(t)
+	provider := newMockOIDCProvider(t)
+	service := newTestOIDCService(t, provider)
+	ctx := context.Background()
+
+	owner := createTestUser(t, db, "owner@example.com")
+	other := createTestUser(t, db, "other@example.com")
+
+	startLink := func(userID uint) *models.OIDCAuthorizationResponse {
+		t.Helper()
+		auth, err := service.StartLink(ctx, mockProvider, userID)
+		if err != nil {
+			t.Fatalf("StartLink: %v", err)
+		}
+		return auth
+	}
+
+	// The provider does not vouch for the address, which is fine for linking
+	// but must not verify the account
+	claims := verifiedClaims("subject-7", "someone-else@example.com")
+	claims["email_verified"] = false
+	identity, err := service.CompleteLink(ctx, mockProvider, owner.ID, provider.authorize(t, startLink(owner.ID), claims))
+	if err != nil {
+		t.Fatalf("CompleteLink: %v", err)
+	}
+	if identity.UserID != owner.ID || identity.Provider != mockProvider {
+		t.Errorf("linked identity %+v, want %s for user %d", identity, mockProvider, owner.ID)
+	}
+	var reloaded models.User
+	if err := db.First(&reloaded, owner.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.IsEmailVerified() {
+		t.Error("linking an unverified provider email verified the account")
+	}
+
+	// A link flow belongs to the user who started it, and a login flow
+	// cannot be used to link
+	callback := provider.authorize(t, startLink(owner.ID), verifiedClaims("subject-8", "other@example.com"))
+	if _, err := service.CompleteLink(ctx, mockProvider, other.ID, callback); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("someone else's state: CompleteLink = %v, want %v", err, ErrInvalidOIDCState)
+	}
+	callback = provider.authorize(t, startOIDCLogin(t, service), verifiedClaims("subject-8", "other@example.com"))
+	if _, err := service.CompleteLink(ctx, mockProvider, other.ID, callback); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("login state: CompleteLink = %v, want %v", err, ErrInvalidOIDCState)
+	}
+
+	callback = provider.authorize(t, startLink(other.ID), verifiedClaims("subject-7", "other@example.com"))
+	if _, err := service.CompleteLink(ctx, mockProvider, other.ID, callback); !errors.Is(err, ErrIdentityLinkedElsewhere) {
+		t.Errorf("identity of another user: CompleteLink = %v, want %v", err, ErrIdentityLinkedElsewhere)
+	}
+
+	claims = verifiedClaims("subject-9", "owner@example.com")
+	claims["nonce"] = "not-the-nonce-we-sent"
+	if _, err := service.CompleteLink(ctx, mockProvider, owner.ID, provider.authorize(t, startLink(owner.ID), claims)); !errors.Is(err, ErrOIDCAuthFailed) {
+		t.Errorf("bad nonce: CompleteLink = %v, want %v", err, ErrOIDCAuthFailed)
+	}
+
+	callback = provider.authorize(t, startLink(owner.ID), verifiedClaims("subject-9", "owner@example.com"))
+	if _, err := service.CompleteLink(ctx, mockProvider, owner.ID, callback); !errors.Is(err, ErrProviderAlreadyLinked) {
+		t.Errorf("second identity for the provider: CompleteLink = %v, want %v", err, ErrProviderAlreadyLinked)
+	}
+}
+
+func TestUnlink(t *testing.T) {
+	db := testDB(t)
+	provider := newMockOIDCProvider(t)
+	service := newTestOIDCService(t, provider)
+
+	callback := provider.authorize(t, startOIDCLogin(t, service), verifiedClaims("subject-10", "passwordless@example.com"))
+	result, err := service.CompleteLogin(context.Background(), mockProvider, callback, ClientMeta{})
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+
+	if err := service.Unlink(result.User.ID, "other"); !errors.Is(err, ErrProviderNotLinked) {
+		t.Errorf("provider not linked: Unlink = %v, want %v", err, ErrProviderNotLinked)
+	}
+	if err := service.Unlink(result.User.ID, mockProvider); !errors.Is(err, ErrLastSignInMethod) {
+		t.Errorf("only sign-in method: Unlink = %v, want %v", err, ErrLastSignInMethod)
+	}
+
+	if err := db.Model(&models.User{}).Where("id = ?", result.User.ID).Update("password", "not-a-real-hash").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Unlink(result.User.ID, mockProvider); err != nil {
+		t.Errorf("Unlink with a password set: %v", err)
+	}
+}
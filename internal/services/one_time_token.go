@@ -0,0 +1,75 @@
+package services
+
+import (
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// issueOneTimeToken creates a new single-use token for the user and
+// invalidates every earlier token issued for the same purpose
+func issueOneTimeToken(db *gorm.DB, user *models.User, purpose string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := invalidateOneTimeTokens(tx, user.ID, purpose); err != nil {
+			return err
+		}
+
+		oneTimeToken := models.OneTimeToken{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			Email:     user.Email,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: time.Now().Add(ttl),
+		}
+		return tx.Create(&oneTimeToken).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeOneTimeToken marks the token as used and returns it. It fails for
+// unknown, expired or already used tokens, including when two requests race.
+func consumeOneTimeToken(db *gorm.DB, token, purpose string) (*models.OneTimeToken, error) {
+	var stored models.OneTimeToken
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND purpose = ?", utils.HashToken(token), purpose).
+		First(&stored).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errInvalidOneTimeToken
+		}
+		return nil, err
+	}
+
+	if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, errInvalidOneTimeToken
+	}
+
+	result := db.Model(&stored).Where("used_at IS NULL").Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errInvalidOneTimeToken
+	}
+
+	return &stored, nil
+}
+
+// invalidateOneTimeTokens burns every outstanding token of the user for purpose
+func invalidateOneTimeTokens(db *gorm.DB, userID uint, purpose string) error {
+	return db.Model(&models.OneTimeToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
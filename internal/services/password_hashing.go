@@ -0,0 +1,31 @@
+package services
+
+import (
+	"errors"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/utils"
+)
+
+// ConfigurePasswordHashing selects the hasher used for new passwords.
+// Hashes already stored keep verifying with the algorithm encoded in them.
+func ConfigurePasswordHashing(cfg *config.Config) error {
+	params := utils.DefaultArgon2idParams
+
+	if cfg.PasswordHashAlgorithm == utils.PasswordAlgorithmArgon2id {
+		if cfg.Argon2Memory < 8 || cfg.Argon2Iterations < 1 || cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > 255 {
+			return errors.New("invalid argon2id parameters")
+		}
+		params.Memory = uint32(cfg.Argon2Memory)
+		params.Iterations = uint32(cfg.Argon2Iterations)
+		params.Parallelism = uint8(cfg.Argon2Parallelism)
+	}
+
+	hasher, err := utils.NewPasswordHasher(cfg.PasswordHashAlgorithm, params, cfg.BcryptCost)
+	if err != nil {
+		return err
+	}
+
+	utils.SetPasswordHasher(hasher)
+	return nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+)
+
+// bcrypt silently ignores everything after the first 72 bytes
+const maxPasswordBytes = 72
+
+// PasswordPolicyError lists every rule a password broke
+type PasswordPolicyError struct {
+	Violations []models.FieldError
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the password policy"
+}
+
+// PasswordPolicy enforces the configured password rules wherever a password is set
+type PasswordPolicy struct {
+	cfg      *config.Config
+	breached *utils.BreachedPasswordList
+}
+
+var (
+	sharedPasswordPolicy *PasswordPolicy
+	passwordPolicyOnce   sync.Once
+)
+
+func getPasswordPolicy(cfg *config.Config) *PasswordPolicy {
+	passwordPolicyOnce.Do(func() {
+		policy := &PasswordPolicy{cfg: cfg}
+
+		if cfg.PasswordCheckBreached {
+			breached, err := utils.LoadBreachedPasswordList(cfg.BreachedPasswordsPath)
+			if err != nil {
+				// Fall back to the bundled list rather than refusing every password
+				log.Printf("Failed to load breached passwords from %s: %v", cfg.BreachedPasswordsPath, err)
+				breached, _ = utils.LoadBreachedPasswordList("")
+			}
+			policy.breached = breached
+		}
+
+		sharedPasswordPolicy = policy
+	})
+	return sharedPasswordPolicy
+}
+
+// Validate checks password against the policy for user, reporting violations
+// under field. It returns a *PasswordPolicyError when any rule is broken.
+func (p *PasswordPolicy) Validate(field, password string, user *models.User) error {
+	var violations []models.FieldError
+	add := func(code, message string) {
+		violations = append(violations, models.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	if password == "" {
+		add("required", "Password is required")
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	if utf8.RuneCountInString(password) < p.cfg.PasswordMinLength {
+		add("min_length", fmt.Sprintf("Password must be at least %d characters long", p.cfg.PasswordMinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		add("max_length", fmt.Sprintf("Password must be at most %d bytes long", maxPasswordBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.cfg.PasswordRequireUpper && !hasUpper {
+		add("uppercase", "Password must contain an uppercase letter")
+	}
+	if p.cfg.PasswordRequireLower && !hasLower {
+		add("lowercase", "Password must contain a lowercase letter")
+	}
+	if p.cfg.PasswordRequireDigit && !hasDigit {
+		add("digit", "Password must contain a digit")
+	}
+	if p.cfg.PasswordRequireSymbol && !hasSymbol {
+		add("symbol", "Password must contain a symbol")
+	}
+
+	classes := 0
+	for _, present := range []bool{hasUpper, hasLower, hasDigit, hasSymbol} {
+		if present {
+			classes++
+		}
+	}
+	if classes < p.cfg.PasswordMinCharClasses {
+		add("char_classes", fmt.Sprintf("Password must contain at least %d of: uppercase letters, lowercase letters, digits, symbols", p.cfg.PasswordMinCharClasses))
+	}
+
+	if user != nil && containsPersonalInfo(password, user) {
+		add("personal_info", "Password must not contain your email address or name")
+	}
+
+	if p.breached != nil && p.breached.Contains(password) {
+		add("breached", "Password has appeared in a data breach, please choose a different one")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// containsPersonalInfo reports whether the password contains the local part
+// of the email or the user's first or last name. Very short values are
+// ignored, they would reject too many unrelated passwords.
+func containsPersonalInfo(password string, user *models.User) bool {
+	lowered := strings.ToLower(password)
+
+	localPart, _, _ := strings.Cut(user.Email, "@")
+	for _, value := range []string{localPart, user.FirstName, user.LastName} {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if utf8.RuneCountInString(value) >= 3 && strings.Contains(lowered, value) {
+			return true
+		}
+	}
+	return false
+}
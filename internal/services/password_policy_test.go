@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+)
+
+// violationCodes returns the codes of the rules err reports as broken
+func violationCodes(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Validate = %v, want a *PasswordPolicyError", err)
+	}
+	var codes []string
+	for _, violation := range policyErr.Violations {
+		if violation.Field != "password" {
+			t.Errorf("violation %s reported under %q", violation.Code, violation.Field)
+		}
+		codes = append(codes, violation.Code)
+	}
+	return codes
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	breached, err := utils.LoadBreachedPasswordList("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := &PasswordPolicy{
+		cfg:      &config.Config{PasswordMinLength: 8, PasswordMinCharClasses: 2},
+		breached: breached,
+	}
+	user := &models.User{Email: "ada.lovelace@example.com", FirstName: "Al", LastName: "Byron"}
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"strong", "tangerine-Orbit", nil},
+		{"empty", "", []string{"required"}},
+		{"short", "ab1", []string{"min_length"}},
+		{"length counts runes", "éééééé1", []string{"min_length"}},
+		{"long enough in runes", "éééééééé1", nil},
+		{"over the bcrypt limit", "a1" + strings.Repeat("x", 71), []string{"max_length"}},
+		{"one character class", "tangerineorbit", []string{"char_classes"}},
+		{"spaces are no class", "tangerine orbit", []string{"char_classes"}},
+		{"short and one class", "abc", []string{"min_length", "char_classes"}},
+		{"email local part", "ada.lovelace-99", []string{"personal_info"}},
+		{"last name in any case", "BYRON-rules-ok", []string{"personal_info"}},
+		{"short names are ignored", "Al-is-short-42", nil},
+		{"breached", "Password1", []string{"breached"}},
+		{"breached and one class", "password", []string{"char_classes", "breached"}},
+	}
+
+	for _, tt := range tests {
+		got := violationCodes(t, policy.Validate("password", tt.password, user))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Validate(%q) broke %v, want %v", tt.name, tt.password, got, tt.want)
+		}
+	}
+
+	// Without a user there is nothing personal to compare against
+	if err := policy.Validate("password", "ada.lovelace-99", nil); err != nil {
+		t.Errorf("no user: Validate = %v", err)
+	}
+}
+
+func TestPasswordPolicyRequiredClasses(t *testing.T) {
+	policy := &PasswordPolicy{cfg: &config.Config{
+		PasswordMinLength:     4,
+		PasswordRequireUpper:  true,
+		PasswordRequireLower:  true,
+		PasswordRequireDigit:  true,
+		PasswordRequireSymbol: true,
+	}}
+
+	tests := []struct {
+		password string
+		want     []string
+	}{
+		{"Ab1!", nil},
+		{"ab1!", []string{"uppercase"}},
+		{"AB1!", []string{"lowercase"}},
+		{"Abc!", []string{"digit"}},
+		{"Ab12", []string{"symbol"}},
+		{"    ", []string{"uppercase", "lowercase", "digit", "symbol"}},
+	}
+
+	for _, tt := range tests {
+		got := violationCodes(t, policy.Validate("password", tt.password, nil))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Validate(%q) broke %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
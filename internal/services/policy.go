@@ -0,0 +1,44 @@
+package services
+
+import "go-fiber-boilerplate/internal/models"
+
+// Actor identifies the authenticated user performing an action. Scopes is
+// only set for API key requests and further narrows what the role allows.
+type Actor struct {
+	UserID uint
+	Role   models.Role
+	Scopes []models.Permission
+}
+
+// Can reports whether the actor's role, and API key scopes if any, grant the given permission
+func (a Actor) Can(permission models.Permission) bool {
+	if !a.Role.HasPermission(permission) {
+		return false
+	}
+	if a.Scopes == nil {
+		return true
+	}
+	for _, scope := range a.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// canViewBlog hides drafts from everyone but their author and admins
+func canViewBlog(actor Actor, blog *models.Blog) bool {
+	return blog.Published || (actor.UserID != 0 && blog.UserID == actor.UserID) || actor.Can(models.PermissionBlogReadAny)
+}
+
+func canUpdateBlog(actor Actor, blog *models.Blog) bool {
+	return (blog.UserID == actor.UserID && actor.Can(models.PermissionBlogUpdate)) || actor.Can(models.PermissionBlogUpdateAny)
+}
+
+func canPublishBlog(actor Actor, blog *models.Blog) bool {
+	return (blog.UserID == actor.UserID && actor.Can(models.PermissionBlogUpdate)) || actor.Can(models.PermissionBlogPublishAny)
+}
+
+func canDeleteBlog(actor Actor, blog *models.Blog) bool {
+	return (blog.UserID == actor.UserID && actor.Can(models.PermissionBlogDelete)) || actor.Can(models.PermissionBlogDeleteAny)
+}
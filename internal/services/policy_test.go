@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+
+	"go-fiber-boilerplate/internal/models"
+)
+
+func TestActorCan(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      Actor
+		permission models.Permission
+		want       bool
+	}{
+		{"role grants it", Actor{Role: models.RoleAuthor}, models.PermissionBlogCreate, true},
+		{"role lacks it", Actor{Role: models.RoleAuthor}, models.PermissionBlogUpdateAny, false},
+		{"no role", Actor{}, models.PermissionBlogCreate, false},
+		{"scope and role grant it", Actor{Role: models.RoleAuthor, Scopes: []models.Permission{models.PermissionBlogCreate}}, models.PermissionBlogCreate, true},
+		{"scope narrows the role", Actor{Role: models.RoleAdmin, Scopes: []models.Permission{models.PermissionBlogCreate}}, models.PermissionUserManage, false},
+		{"scope cannot widen the role", Actor{Role: models.RoleAuthor, Scopes: []models.Permission{models.PermissionBlogDeleteAny}}, models.PermissionBlogDeleteAny, false},
+		{"empty scopes grant nothing", Actor{Role: models.RoleAdmin, Scopes: []models.Permission{}}, models.PermissionBlogCreate, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.actor.Can(tt.permission); got != tt.want {
+			t.Errorf("%s: Can(%s) = %v, want %v", tt.name, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestBlogPolicies(t *testing.T) {
+	const owner, stranger = 1, 2
+	draft := &models.Blog{UserID: owner}
+	published := &models.Blog{UserID: owner, Published: true}
+	scoped := func(role models.Role, id uint, scopes ...models.Permission) Actor {
+		return Actor{UserID: id, Role: role, Scopes: append([]models.Permission{}, scopes...)}
+	}
+
+	// want lists view draft, view published, update, publish, delete
+	tests := []struct {
+		name  string
+		actor Actor
+		want  [5]bool
+	}{
+		{"anonymous", Actor{}, [5]bool{false, true, false, false, false}},
+		{"owning author", Actor{UserID: owner, Role: models.RoleAuthor}, [5]bool{true, true, true, true, true}},
+		{"other author", Actor{UserID: stranger, Role: models.RoleAuthor}, [5]bool{false, true, false, false, false}},
+		{"other editor", Actor{UserID: stranger, Role: models.RoleEditor}, [5]bool{false, true, true, true, false}},
+		{"other admin", Actor{UserID: stranger, Role: models.RoleAdmin}, [5]bool{true, true, true, true, true}},
+
+		// API keys only get what their scopes and the role both allow
+		{"owner's key without scopes", scoped(models.RoleAuthor, owner), [5]bool{true, true, false, false, false}},
+		{"owner's update key", scoped(models.RoleAuthor, owner, models.PermissionBlogUpdate), [5]bool{true, true, true, true, false}},
+		{"owner's delete key", scoped(models.RoleAuthor, owner, models.PermissionBlogDelete), [5]bool{true, true, false, false, true}},
+		{"admin key for own blogs", scoped(models.RoleAdmin, stranger, models.PermissionBlogUpdate, models.PermissionBlogDelete), [5]bool{false, true, false, false, false}},
+		{"admin key to publish any", scoped(models.RoleAdmin, stranger, models.PermissionBlogPublishAny), [5]bool{false, true, false, true, false}},
+		{"admin key to moderate", scoped(models.RoleAdmin, stranger, models.PermissionBlogReadAny, models.PermissionBlogDeleteAny), [5]bool{true, true, false, false, true}},
+		{"author key claiming any", scoped(models.RoleAuthor, stranger, models.PermissionBlogUpdateAny, models.PermissionBlogDeleteAny), [5]bool{false, true, false, false, false}},
+	}
+
+	for _, tt := range tests {
+		got := [5]bool{
+			canViewBlog(tt.actor, draft),
+			canViewBlog(tt.actor, published),
+			canUpdateBlog(tt.actor, draft),
+			canPublishBlog(tt.actor, draft),
+			canDeleteBlog(tt.actor, draft),
+		}
+		if got != tt.want {
+			t.Errorf("%s: view draft, view published, update, publish, delete = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
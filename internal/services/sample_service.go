@@ -44,7 +44,7 @@ func (s *SampleService) GetSample(id int) (*models.Sample, error) {
 		Preload("User").
 		First(&sample, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, gorm.ErrRecordNotFound
+			return nil, ErrSampleNotFound
 		}
 		return nil, err
 	}
@@ -70,13 +70,13 @@ func (s *SampleService) UpdateSample(userID uint, id int, req models.UpdateSampl
 	var sample models.Sample
 	if err := database.GetDB().First(&sample, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, gorm.ErrRecordNotFound
+			return nil, ErrSampleNotFound
 		}
 		return nil, err
 	}
 
 	if sample.UserID != userID {
-		return nil, errors.New("forbidden")
+		return nil, ErrSampleForbidden
 	}
 
 	if req.Title != "" {
@@ -98,13 +98,13 @@ func (s *SampleService) DeleteSample(userID uint, id int) error {
 	var sample models.Sample
 	if err := database.GetDB().First(&sample, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return gorm.ErrRecordNotFound
+			return ErrSampleNotFound
 		}
 		return err
 	}
 
 	if sample.UserID != userID {
-		return errors.New("forbidden")
+		return ErrSampleForbidden
 	}
 
 	if err := database.GetDB().Delete(&sample).Error; err != nil {
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// How often a session's last_seen_at is written at most
+const sessionTouchInterval = time.Minute
+
+type SessionService struct {
+	cfg *config.Config
+}
+
+func NewSessionService(cfg *config.Config) *SessionService {
+	return &SessionService{cfg: cfg}
+}
+
+// GetSessions lists the user's active sessions, most recently used first
+func (s *SessionService) GetSessions(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	if err := database.GetDB().
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs the user out on one device
+func (s *SessionService) RevokeSession(userID uint, sessionID string) error {
+	var session models.Session
+	if err := database.GetDB().
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrSessionNotFound
+		}
+		return ErrDatabase.Wrap(err)
+	}
+
+	if err := revokeSession(database.GetDB(), &session); err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+	return nil
+}
+
+// createSession records a new session for the device described by meta
+func createSession(db *gorm.DB, userID uint, sessionID string, meta ClientMeta, expiresAt time.Time) error {
+	now := time.Now()
+	session := models.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		UserAgent:  truncate(meta.UserAgent, 512),
+		IPAddress:  truncate(meta.IP, 64),
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+	return db.Create(&session).Error
+}
+
+// refreshSession extends the session after a refresh token rotation. Families
+// started before sessions existed get their session record created here.
+func refreshSession(db *gorm.DB, userID uint, sessionID string, meta ClientMeta, expiresAt time.Time) error {
+	result := db.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Updates(map[string]interface{}{
+			"ip_address":   truncate(meta.IP, 64),
+			"last_seen_at": time.Now(),
+			"expires_at":   expiresAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&models.Session{}).Where("id = ?", sessionID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSessionRevoked
+	}
+	return createSession(db, userID, sessionID, meta, expiresAt)
+}
+
+// revokeSession marks the session revoked, kills its refresh token family and
+// makes AuthMiddleware reject access tokens carrying its sid
+func revokeSession(db *gorm.DB, session *models.Session) error {
+	now := time.Now()
+	if err := db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", session.ID).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+
+	if err := db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", session.ID).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+
+	GetRevocationStore().RevokeSession(session.ID, session.ExpiresAt)
+	return nil
+}
+
+// revokeSessionByID revokes a session known only by its ID, e.g. a refresh
+// token family. Unknown IDs are ignored.
+func revokeSessionByID(db *gorm.DB, sessionID string) error {
+	var session models.Session
+	if err := db.Where("id = ?", sessionID).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+	return revokeSession(db, &session)
+}
+
+var (
+	sessionTouchMu sync.Mutex
+	sessionTouches = make(map[string]time.Time)
+)
+
+// TouchSession records activity on a session. The write happens in the
+// background and at most once per sessionTouchInterval, so calling it on
+// every request is cheap.
+func TouchSession(sessionID string) {
+	if sessionID == "" {
+		return
+	}
+
+	now := time.Now()
+
+	sessionTouchMu.Lock()
+	if last, exists := sessionTouches[sessionID]; exists && now.Sub(last) < sessionTouchInterval {
+		sessionTouchMu.Unlock()
+		return
+	}
+	sessionTouches[sessionID] = now
+	if len(sessionTouches) > 10000 {
+		for id, last := range sessionTouches {
+			if now.Sub(last) >= sessionTouchInterval {
+				delete(sessionTouches, id)
+			}
+		}
+	}
+	sessionTouchMu.Unlock()
+
+	go func() {
+		if err := database.GetDB().Model(&models.Session{}).
+			Where("id = ?", sessionID).
+			Update("last_seen_at", now).Error; err != nil {
+			log.Printf("Failed to update session last seen: %v", err)
+		}
+	}()
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+
+	"github.com/gosimple/slug"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxTagLength      = 64
+	maxCategoryLength = 100
+)
+
+type TaxonomyService struct {
+	cfg *config.Config
+}
+
+func NewTaxonomyService(cfg *config.Config) *TaxonomyService {
+	return &TaxonomyService{cfg: cfg}
+}
+
+// GetTags lists tags with how many published blogs carry them, most used first
+func (s *TaxonomyService) GetTags(params models.TagQueryParams) ([]models.Tag, int64, error) {
+	query := database.GetDB().Model(&models.Tag{})
+	if search := strings.TrimSpace(params.Search); search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("tags.name ILIKE ? OR tags.slug ILIKE ?", pattern, pattern)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tags []models.Tag
+	if err := query.
+		Select("tags.*, COUNT(blogs.id) AS post_count").
+		Joins("LEFT JOIN blog_tags ON blog_tags.tag_id = tags.id").
+		Joins("LEFT JOIN blogs ON blogs.id = blog_tags.blog_id AND blogs.published AND blogs.deleted_at IS NULL").
+		Group("tags.id").
+		Order("post_count DESC, tags.name ASC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&tags).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return tags, total, nil
+}
+
+func (s *TaxonomyService) RenameTag(actor Actor, meta ClientMeta, id uint, req models.RenameTagRequest) (*models.Tag, error) {
+	tag, err := findTag(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name, tagSlug, err := normalizeTag(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if req.Slug != "" {
+		tagSlug = req.Slug
+	}
+
+	previous := tag.Name
+	tag.Name = name
+	tag.Slug = tagSlug
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(tag).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, meta, AuditTagRenamed, "tag", tag.ID, map[string]interface{}{
+			"from": previous,
+			"to":   tag.Name,
+		})
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrTagSlugTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// MergeTags moves the blogs of one tag to another and deletes the first,
+// e.g. to fold "golang" into "go"
+func (s *TaxonomyService) MergeTags(actor Actor, meta ClientMeta, id uint, req models.MergeTagRequest) (*models.Tag, error) {
+	if id == req.IntoID {
+		return nil, ErrTagMergeSelf
+	}
+
+	source, err := findTag(id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := findTag(req.IntoID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		// Blogs that already carry both tags keep a single link
+		if err := tx.Exec(`INSERT INTO blog_tags (blog_id, tag_id)
+			SELECT blog_id, ? FROM blog_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, target.ID, source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM blog_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(source).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, meta, AuditTagMerged, "tag", target.ID, map[string]interface{}{
+			"merged": source.Slug,
+			"into":   target.Slug,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// GetCategories returns every category with how many published blogs it
+// holds directly
+func (s *TaxonomyService) GetCategories() ([]models.Category, error) {
+	var categories []models.Category
+	if err := database.GetDB().
+		Model(&models.Category{}).
+		Select("categories.*, COUNT(blogs.id) AS post_count").
+		Joins("LEFT JOIN blogs ON blogs.category_id = categories.id AND blogs.published AND blogs.deleted_at IS NULL").
+		Group("categories.id").
+		Order("categories.name ASC").
+		Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (s *TaxonomyService) CreateCategory(actor Actor, meta ClientMeta, req models.CreateCategoryRequest) (*models.Category, error) {
+	category := models.Category{Name: strings.TrimSpace(req.Name)}
+
+	category.Slug = req.Slug
+	if category.Slug == "" {
+		category.Slug = truncateSlug(slug.Make(category.Name), maxCategoryLength)
+	}
+	if category.Slug == "" {
+		return nil, ErrInvalidCategory
+	}
+
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if _, err := findCategory(*req.ParentID); err != nil {
+			return nil, err
+		}
+		category.ParentID = req.ParentID
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, meta, AuditCategoryCreated, "category", category.ID, map[string]interface{}{
+			"slug": category.Slug,
+		})
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrCategorySlugTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (s *TaxonomyService) UpdateCategory(actor Actor, meta ClientMeta, id uint, req models.UpdateCategoryRequest) (*models.Category, error) {
+	category, err := findCategory(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		category.Name = name
+	}
+	if req.Slug != "" {
+		category.Slug = req.Slug
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			category.ParentID = nil
+		} else {
+			if err := checkCategoryParent(category.ID, *req.ParentID); err != nil {
+				return nil, err
+			}
+			category.ParentID = req.ParentID
+		}
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(category).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, meta, AuditCategoryUpdated, "category", category.ID, map[string]interface{}{
+			"slug":      category.Slug,
+			"parent_id": category.ParentID,
+		})
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrCategorySlugTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory removes a category. Its subcategories move up to its parent
+// and its blogs are left without a category.
+func (s *TaxonomyService) DeleteCategory(actor Actor, meta ClientMeta, id uint) error {
+	category, err := findCategory(id)
+	if err != nil {
+		return err
+	}
+
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", category.ID).
+			Update("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Blog{}).Unscoped().Where("category_id = ?", category.ID).
+			UpdateColumn("category_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(category).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, meta, AuditCategoryDeleted, "category", category.ID, map[string]interface{}{
+			"slug": category.Slug,
+		})
+	})
+}
+
+// checkCategoryParent refuses a parent that is the category itself or lies
+// below it, which would turn the tree into a loop
+func checkCategoryParent(id, parentID uint) error {
+	if _, err := findCategory(parentID); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := database.GetDB().Raw(`WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE id = ?
+			UNION
+			SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+		) SELECT COUNT(*) FROM tree WHERE id = ?`, id, parentID).Scan(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCategoryCycle
+	}
+	return nil
+}
+
+func findTag(id uint) (*models.Tag, error) {
+	var tag models.Tag
+	if err := database.GetDB().First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func findCategory(id uint) (*models.Category, error) {
+	var category models.Category
+	if err := database.GetDB().First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// normalizeTag tidies the whitespace of a tag name and derives its slug
+func normalizeTag(name string) (string, string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || utf8.RuneCountInString(name) > maxTagLength {
+		return "", "", ErrInvalidTag
+	}
+
+	tagSlug := truncateSlug(slug.Make(name), maxTagLength)
+	if tagSlug == "" {
+		return "", "", ErrInvalidTag
+	}
+	return name, tagSlug, nil
+}
+
+// truncateSlug keeps a slug within its column, which transliteration can
+// push past the length of the name it came from
+func truncateSlug(value string, size int) string {
+	if len(value) > size {
+		value = strings.TrimRight(value[:size], "-")
+	}
+	return value
+}
+
+// resolveTags finds the tags with the given names, creating the missing ones.
+// Names that only differ in spelling, like "Go" and "go", are the same tag,
+// and the first spelling used is kept.
+func resolveTags(db *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	seen := map[string]bool{}
+
+	for _, raw := range names {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		name, tagSlug, err := normalizeTag(raw)
+		if err != nil {
+			return nil, err
+		}
+		if seen[tagSlug] {
+			continue
+		}
+		seen[tagSlug] = true
+
+		tag := models.Tag{Name: name, Slug: tagSlug}
+		if err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "slug"}},
+			DoNothing: true,
+		}).Create(&tag).Error; err != nil {
+			return nil, err
+		}
+		if tag.ID == 0 {
+			if err := db.Where("slug = ?", tagSlug).First(&tag).Error; err != nil {
+				return nil, err
+			}
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// resolveCategory looks up the category a blog is filed under by its slug
+func resolveCategory(db *gorm.DB, value string) (*uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var category models.Category
+	if err := db.Where("slug = ?", value).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUnknownCategory
+		}
+		return nil, err
+	}
+	return &category.ID, nil
+}
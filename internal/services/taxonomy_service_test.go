@@ -0,0 +1,262 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		raw        string
+		name, slug string
+		err        error
+	}{
+		{"Go", "Go", "go", nil},
+		{"  Web \t dev ", "Web dev", "web-dev", nil},
+		{"Café", "Café", "cafe", nil},
+		{strings.Repeat("x", maxTagLength), strings.Repeat("x", maxTagLength), strings.Repeat("x", maxTagLength), nil},
+		{strings.Repeat("x", maxTagLength+1), "", "", ErrInvalidTag},
+		{"   ", "", "", ErrInvalidTag},
+		{"!!!", "", "", ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		name, slug, err := normalizeTag(tt.raw)
+		if name != tt.name || slug != tt.slug || !errors.Is(err, tt.err) {
+			t.Errorf("normalizeTag(%q) = %q, %q, %v; want %q, %q, %v", tt.raw, name, slug, err, tt.name, tt.slug, tt.err)
+		}
+	}
+}
+
+func TestTruncateSlug(t *testing.T) {
+	tests := []struct {
+		value string
+		size  int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"cut-here", 5, "cut-h"},
+		{"no-dash-at-end", 8, "no-dash"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateSlug(tt.value, tt.size); got != tt.want {
+			t.Errorf("truncateSlug(%q, %d) = %q, want %q", tt.value, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestResolveTagsReusesTags(t *testing.T) {
+	db := testDB(t)
+
+	first, err := resolveTags(db, []string{"Go", "go", " ", "Web  dev"})
+	if err != nil {
+		t.Fatalf("resolveTags: %v", err)
+	}
+	if len(first) != 2 || first[0].Name != "Go" || first[1].Slug != "web-dev" {
+		t.Fatalf("resolveTags = %+v, want Go and web-dev", first)
+	}
+
+	// Another spelling finds the same tag and keeps the first one
+	again, err := resolveTags(db, []string{"GO"})
+	if err != nil {
+		t.Fatalf("resolveTags: %v", err)
+	}
+	if len(again) != 1 || again[0].ID != first[0].ID || again[0].Name != "Go" {
+		t.Errorf("resolveTags(GO) = %+v, want tag %d named Go", again, first[0].ID)
+	}
+
+	if _, err := resolveTags(db, []string{"ok", "???"}); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("resolveTags with a bad name = %v, want %v", err, ErrInvalidTag)
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	db := testDB(t)
+	admin := createTestUser(t, db, "admin@example.com")
+	admin.Role = models.RoleAdmin
+	service := NewTaxonomyService(&config.Config{})
+
+	createTestBlog(t, admin, models.CreateBlogRequest{Title: "Only golang", Tags: []string{"golang"}, Published: true})
+	createTestBlog(t, admin, models.CreateBlogRequest{Title: "Both", Tags: []string{"golang", "go"}, Published: true})
+	createTestBlog(t, admin, models.CreateBlogRequest{Title: "Untagged", Published: true})
+
+	var goTag, golangTag models.Tag
+	if err := db.Where("slug = ?", "go").First(&goTag).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Where("slug = ?", "golang").First(&golangTag).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.MergeTags(actorFor(admin), ClientMeta{}, goTag.ID, models.MergeTagRequest{IntoID: goTag.ID}); !errors.Is(err, ErrTagMergeSelf) {
+		t.Errorf("merging a tag into itself = %v, want %v", err, ErrTagMergeSelf)
+	}
+
+	into, err := service.MergeTags(actorFor(admin), ClientMeta{}, golangTag.ID, models.MergeTagRequest{IntoID: goTag.ID})
+	if err != nil {
+		t.Fatalf("MergeTags: %v", err)
+	}
+	if into.ID != goTag.ID {
+		t.Errorf("merged into tag %d, want %d", into.ID, goTag.ID)
+	}
+
+	if got := listBlogs(t, Actor{}, models.BlogQueryParams{Tag: "go", Sort: models.BlogSortTitle}); !reflect.DeepEqual(got, []string{"Both", "Only golang"}) {
+		t.Errorf("blogs tagged go = %v, want [Both Only golang]", got)
+	}
+	if got := listBlogs(t, Actor{}, models.BlogQueryParams{Tag: "golang"}); len(got) != 0 {
+		t.Errorf("blogs tagged golang = %v, want none", got)
+	}
+	if _, err := findTag(golangTag.ID); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("merged tag still there: findTag = %v", err)
+	}
+
+	var links int64
+	if err := db.Table("blog_tags").Where("tag_id = ?", goTag.ID).Count(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+	if links != 2 {
+		t.Errorf("go has %d blog links, want 2", links)
+	}
+}
+
+// createCategoryTree files root > child > grandchild, plus a separate other
+func createCategoryTree(t *testing.T, actor Actor) map[string]*models.Category {
+	t.Helper()
+
+	service := NewTaxonomyService(&config.Config{})
+	categories := map[string]*models.Category{}
+	for _, c := range []struct{ name, parent string }{
+		{"Root", ""},
+		{"Child", "Root"},
+		{"Grandchild", "Child"},
+		{"Other", ""},
+	} {
+		req := models.CreateCategoryRequest{Name: c.name}
+		if c.parent != "" {
+			req.ParentID = &categories[c.parent].ID
+		}
+		category, err := service.CreateCategory(actor, ClientMeta{}, req)
+		if err != nil {
+			t.Fatalf("CreateCategory %q: %v", c.name, err)
+		}
+		categories[c.name] = category
+	}
+	return categories
+}
+
+func TestCheckCategoryParent(t *testing.T) {
+	db := testDB(t)
+	admin := createTestUser(t, db, "admin@example.com")
+	admin.Role = models.RoleAdmin
+	categories := createCategoryTree(t, actorFor(admin))
+
+	tests := []struct {
+		category, parent string
+		want             error
+	}{
+		{"Root", "Root", ErrCategoryCycle},
+		{"Root", "Child", ErrCategoryCycle},
+		{"Root", "Grandchild", ErrCategoryCycle},
+		{"Child", "Grandchild", ErrCategoryCycle},
+		{"Grandchild", "Root", nil},
+		{"Child", "Other", nil},
+		{"Other", "Grandchild", nil},
+	}
+	for _, tt := range tests {
+		if err := checkCategoryParent(categories[tt.category].ID, categories[tt.parent].ID); !errors.Is(err, tt.want) {
+			t.Errorf("checkCategoryParent(%s, %s) = %v, want %v", tt.category, tt.parent, err, tt.want)
+		}
+	}
+
+	if err := checkCategoryParent(categories["Root"].ID, categories["Other"].ID+100); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("missing parent: checkCategoryParent = %v, want %v", err, ErrCategoryNotFound)
+	}
+
+	// UpdateCategory refuses the loop and leaves the tree as it was
+	service := NewTaxonomyService(&config.Config{})
+	grandchild := categories["Grandchild"].ID
+	if _, err := service.UpdateCategory(actorFor(admin), ClientMeta{}, categories["Root"].ID, models.UpdateCategoryRequest{ParentID: &grandchild}); !errors.Is(err, ErrCategoryCycle) {
+		t.Errorf("UpdateCategory into a subcategory = %v, want %v", err, ErrCategoryCycle)
+	}
+	root, err := findCategory(categories["Root"].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.ParentID != nil {
+		t.Errorf("root moved under category %d", *root.ParentID)
+	}
+}
+
+func TestCategoryFilterIncludesSubcategories(t *testing.T) {
+	db := testDB(t)
+	admin := createTestUser(t, db, "admin@example.com")
+	admin.Role = models.RoleAdmin
+	createCategoryTree(t, actorFor(admin))
+
+	for _, b := range []struct{ title, category string }{
+		{"In root", "root"},
+		{"In child", "child"},
+		{"In grandchild", "grandchild"},
+		{"In other", "other"},
+		{"Uncategorized", ""},
+	} {
+		createTestBlog(t, admin, models.CreateBlogRequest{Title: b.title, Category: b.category, Published: true})
+	}
+
+	tests := []struct {
+		category string
+		want     []string
+	}{
+		{"root", []string{"In child", "In grandchild", "In root"}},
+		{"child", []string{"In child", "In grandchild"}},
+		{"grandchild", []string{"In grandchild"}},
+		{"missing", []string{}},
+	}
+	for _, tt := range tests {
+		if got := listBlogs(t, Actor{}, models.BlogQueryParams{Category: tt.category, Sort: models.BlogSortTitle}); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("category %s: got %v, want %v", tt.category, got, tt.want)
+		}
+	}
+
+	if _, err := newTestBlogService().CreateBlog(admin.ID, models.CreateBlogRequest{Title: "Lost", Content: "Lost", Category: "missing"}); !errors.Is(err, ErrUnknownCategory) {
+		t.Errorf("CreateBlog in a missing category = %v, want %v", err, ErrUnknownCategory)
+	}
+}
+
+func TestDeleteCategoryKeepsChildrenAndBlogs(t *testing.T) {
+	db := testDB(t)
+	admin := createTestUser(t, db, "admin@example.com")
+	admin.Role = models.RoleAdmin
+	categories := createCategoryTree(t, actorFor(admin))
+	blog := createTestBlog(t, admin, models.CreateBlogRequest{Title: "In child", Category: "child", Published: true})
+
+	if err := NewTaxonomyService(&config.Config{}).DeleteCategory(actorFor(admin), ClientMeta{}, categories["Child"].ID); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+
+	if _, err := findCategory(categories["Child"].ID); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("deleted category still there: findCategory = %v", err)
+	}
+	grandchild, err := findCategory(categories["Grandchild"].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grandchild.ParentID == nil || *grandchild.ParentID != categories["Root"].ID {
+		t.Errorf("grandchild parent = %v, want %d", grandchild.ParentID, categories["Root"].ID)
+	}
+
+	var kept models.Blog
+	if err := db.First(&kept, blog.ID).Error; err != nil {
+		t.Fatalf("blog of the deleted category: %v", err)
+	}
+	if kept.CategoryID != nil {
+		t.Errorf("blog still filed under category %d", *kept.CategoryID)
+	}
+}
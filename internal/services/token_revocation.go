@@ -0,0 +1,231 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// TokenRevocationStore keeps revoked access tokens in memory so AuthMiddleware
+// can check them without a database round trip. Postgres is the source of truth.
+type TokenRevocationStore struct {
+	mu       sync.RWMutex
+	tokens   map[string]time.Time
+	users    map[uint]userRevocation
+	sessions map[string]time.Time
+}
+
+type userRevocation struct {
+	revokedBefore time.Time
+	expiresAt     time.Time
+}
+
+var (
+	revocationStore     *TokenRevocationStore
+	revocationStoreOnce sync.Once
+)
+
+func GetRevocationStore() *TokenRevocationStore {
+	revocationStoreOnce.Do(func() {
+		revocationStore = &TokenRevocationStore{
+			tokens:   make(map[string]time.Time),
+			users:    make(map[uint]userRevocation),
+			sessions: make(map[string]time.Time),
+		}
+	})
+	return revocationStore
+}
+
+// Load merges the unexpired revocations stored in the database into the cache
+func (s *TokenRevocationStore) Load() error {
+	now := time.Now()
+
+	var revokedTokens []models.RevokedToken
+	if err := database.GetDB().Where("expires_at > ?", now).Find(&revokedTokens).Error; err != nil {
+		return err
+	}
+
+	var userRevocations []models.UserTokenRevocation
+	if err := database.GetDB().Where("expires_at > ?", now).Find(&userRevocations).Error; err != nil {
+		return err
+	}
+
+	var revokedSessions []models.Session
+	if err := database.GetDB().
+		Select("id", "expires_at").
+		Where("revoked_at IS NOT NULL AND expires_at > ?", now).
+		Find(&revokedSessions).Error; err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range revokedSessions {
+		s.sessions[session.ID] = session.ExpiresAt
+	}
+	for _, token := range revokedTokens {
+		s.tokens[token.JTI] = token.ExpiresAt
+	}
+	for _, revocation := range userRevocations {
+		current, exists := s.users[revocation.UserID]
+		if exists && current.revokedBefore.After(revocation.RevokedBefore) {
+			continue
+		}
+		s.users[revocation.UserID] = userRevocation{
+			revokedBefore: revocation.RevokedBefore,
+			expiresAt:     revocation.ExpiresAt,
+		}
+	}
+
+	return nil
+}
+
+// RevokeToken revokes a single access token until it would have expired anyway
+func (s *TokenRevocationStore) RevokeToken(jti string, userID uint, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+
+	revokedToken := models.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+
+	if err := database.GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&revokedToken).Error; err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tokens[jti] = expiresAt
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RevokeAllForUser revokes every access token of the user issued up to now.
+// The entry can be dropped once the longest-lived of those tokens has expired.
+func (s *TokenRevocationStore) RevokeAllForUser(userID uint, tokenTTL time.Duration) error {
+	now := time.Now()
+	revocation := models.UserTokenRevocation{
+		UserID:        userID,
+		RevokedBefore: now.Truncate(time.Microsecond),
+		ExpiresAt:     now.Add(tokenTTL),
+	}
+
+	if err := database.GetDB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_before", "expires_at", "updated_at"}),
+	}).Create(&revocation).Error; err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.users[userID] = userRevocation{
+		revokedBefore: revocation.RevokedBefore,
+		expiresAt:     revocation.ExpiresAt,
+	}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RevokeSession caches a session revocation that has already been written to
+// the sessions table. It is kept until the session itself would have expired.
+func (s *TokenRevocationStore) RevokeSession(sessionID string, expiresAt time.Time) {
+	if sessionID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	s.sessions[sessionID] = expiresAt
+	s.mu.Unlock()
+}
+
+// IsSessionRevoked reports whether the session the token belongs to was revoked
+func (s *TokenRevocationStore) IsSessionRevoked(sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, exists := s.sessions[sessionID]
+	return exists
+}
+
+// IsRevoked reports whether the token was revoked individually or by a logout-all
+func (s *TokenRevocationStore) IsRevoked(jti string, userID uint, issuedAt time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if jti != "" {
+		if _, exists := s.tokens[jti]; exists {
+			return true
+		}
+	}
+
+	// iat is kept to the microsecond, so a login right after the logout-all
+	// is not caught by it
+	if revocation, exists := s.users[userID]; exists && !issuedAt.After(revocation.revokedBefore) {
+		return true
+	}
+
+	return false
+}
+
+// Cleanup removes expired revocations from the cache and the database
+func (s *TokenRevocationStore) Cleanup() error {
+	now := time.Now()
+
+	s.mu.Lock()
+	for jti, expiresAt := range s.tokens {
+		if !expiresAt.After(now) {
+			delete(s.tokens, jti)
+		}
+	}
+	for userID, revocation := range s.users {
+		if !revocation.expiresAt.After(now) {
+			delete(s.users, userID)
+		}
+	}
+	for sessionID, expiresAt := range s.sessions {
+		if !expiresAt.After(now) {
+			delete(s.sessions, sessionID)
+		}
+	}
+	s.mu.Unlock()
+
+	if err := database.GetDB().Where("expires_at <= ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	if err := database.GetDB().Where("expires_at <= ?", now).Delete(&models.Session{}).Error; err != nil {
+		return err
+	}
+	return database.GetDB().Where("expires_at <= ?", now).Delete(&models.UserTokenRevocation{}).Error
+}
+
+// StartCleanup periodically purges expired entries and reloads the cache so
+// revocations made by other API instances are picked up
+func (s *TokenRevocationStore) StartCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.Cleanup(); err != nil {
+				log.Printf("Failed to clean up revoked tokens: %v", err)
+			}
+			if err := s.Load(); err != nil {
+				log.Printf("Failed to reload revoked tokens: %v", err)
+			}
+		}
+	}()
+}
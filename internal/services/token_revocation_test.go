@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/utils"
+)
+
+func newTestRevocationStore() *TokenRevocationStore {
+	return &TokenRevocationStore{
+		tokens:   make(map[string]time.Time),
+		users:    make(map[uint]userRevocation),
+		sessions: make(map[string]time.Time),
+	}
+}
+
+func TestIsRevokedLogoutAll(t *testing.T) {
+	store := newTestRevocationStore()
+	revokedBefore := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	store.users[1] = userRevocation{revokedBefore: revokedBefore, expiresAt: revokedBefore.Add(time.Hour)}
+
+	tests := []struct {
+		name     string
+		userID   uint
+		issuedAt time.Time
+		want     bool
+	}{
+		{"issued before", 1, revokedBefore.Add(-time.Second), true},
+		{"issued at the cutoff", 1, revokedBefore, true},
+		{"issued a moment after", 1, revokedBefore.Add(time.Millisecond), false},
+		{"issued after", 1, revokedBefore.Add(time.Second), false},
+		{"other user", 2, revokedBefore.Add(-time.Second), false},
+	}
+
+	for _, tt := range tests {
+		if got := store.IsRevoked("", tt.userID, tt.issuedAt); got != tt.want {
+			t.Errorf("%s: IsRevoked = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsRevokedSingleToken(t *testing.T) {
+	store := newTestRevocationStore()
+	store.tokens["revoked"] = time.Now().Add(time.Hour)
+
+	if !store.IsRevoked("revoked", 1, time.Now()) {
+		t.Error("revoked token was accepted")
+	}
+	if store.IsRevoked("other", 1, time.Now()) {
+		t.Error("unrevoked token was rejected")
+	}
+}
+
+func TestLoginRightAfterLogoutAll(t *testing.T) {
+	db := testDB(t)
+	user := createTestUser(t, db, "relogin@example.com")
+	service := newTestAuthService(t)
+	store := GetRevocationStore()
+
+	issuedAt := func(token string) time.Time {
+		t.Helper()
+		claims, err := utils.ValidateJWT(token, JWTConfig(service.cfg))
+		if err != nil {
+			t.Fatalf("ValidateJWT: %v", err)
+		}
+		return claims.IssuedAt.Time
+	}
+
+	before := signIn(t, service, user)
+	if err := service.LogoutAll(user.ID); err != nil {
+		t.Fatalf("LogoutAll: %v", err)
+	}
+	after := signIn(t, service, user)
+
+	if !store.IsRevoked("", user.ID, issuedAt(before.Token)) {
+		t.Error("token issued before the logout-all was accepted")
+	}
+	if store.IsRevoked("", user.ID, issuedAt(after.Token)) {
+		t.Error("token issued right after the logout-all was rejected")
+	}
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserService struct {
+	cfg         *config.Config
+	cloudinary  *utils.Cloudinary
+	authService *AuthService
+}
+
+func NewUserService(cfg *config.Config) (*UserService, error) {
+	cloudinary, err := utils.NewCloudinaryService(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cloudinary: %w", err)
+	}
+	return &UserService{
+		cfg:         cfg,
+		cloudinary:  cloudinary,
+		authService: NewAuthService(cfg),
+	}, nil
+}
+
+func (s *UserService) GetProfile(userID uint) (*models.User, error) {
+	var user models.User
+	if err := database.GetDB().First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return &user, nil
+}
+
+func (s *UserService) UpdateProfile(userID uint, req models.UpdateProfileRequest) (*models.User, error) {
+	user, err := s.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if firstName := strings.TrimSpace(req.FirstName); firstName != "" {
+		user.FirstName = firstName
+	}
+
+	if lastName := strings.TrimSpace(req.LastName); lastName != "" {
+		user.LastName = lastName
+	}
+
+	oldAvatarID := user.AvatarID
+	if req.Avatar != nil {
+		uploadResult, err := s.cloudinary.UploadImage(req.Avatar, "avatars")
+		if err != nil {
+			return nil, ErrImageUploadFailed.Wrap(err)
+		}
+		user.AvatarURL = uploadResult.SecureURL
+		user.AvatarID = uploadResult.PublicID
+	}
+
+	if err := database.GetDB().Save(user).Error; err != nil {
+		if req.Avatar != nil {
+			_ = s.cloudinary.DeleteImage(user.AvatarID)
+		}
+		return nil, ErrDatabase.Wrap(err)
+	}
+
+	// The old avatar is only removed once the new one is safely stored
+	if req.Avatar != nil && oldAvatarID != "" {
+		_ = s.cloudinary.DeleteImage(oldAvatarID)
+	}
+
+	return user, nil
+}
+
+func (s *UserService) ChangePassword(userID uint, req models.ChangePasswordRequest) error {
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		return ErrPasswordFieldsRequired
+	}
+
+	user, err := s.GetProfile(userID)
+	if err != nil {
+		return err
+	}
+
+	if !utils.CheckPassword(req.CurrentPassword, user.Password) {
+		return ErrInvalidPassword
+	}
+
+	if err := getPasswordPolicy(s.cfg).Validate("new_password", req.NewPassword, user); err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return ErrInternal.Wrap(err)
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		return invalidateOneTimeTokens(tx, user.ID, models.TokenPurposeResetPassword)
+	})
+	if err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+
+	if err := s.authService.revokeAllSessions(user.ID); err != nil {
+		log.Printf("Failed to revoke sessions for user %d after password change: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// DeleteAccount soft-deletes the caller. Their blogs are transferred to
+// another user, kept under the shared deleted user, or deleted with the account.
+func (s *UserService) DeleteAccount(userID uint, sessionID string, req models.DeleteAccountRequest) error {
+	switch req.BlogAction {
+	case models.BlogActionTransfer, models.BlogActionAnonymize, models.BlogActionCascade:
+	default:
+		return ErrInvalidBlogAction
+	}
+
+	user, err := s.GetProfile(userID)
+	if err != nil {
+		return err
+	}
+
+	// Accounts that only sign in through a provider have no password to
+	// confirm with, so they need to have signed in moments ago instead
+	if user.Password == "" {
+		if err := confirmRecentSignIn(user.ID, sessionID); err != nil {
+			return err
+		}
+	} else if !utils.CheckPassword(req.Password, user.Password) {
+		return ErrInvalidPassword
+	}
+
+	var recipient models.User
+	if req.BlogAction == models.BlogActionTransfer {
+		if req.TransferToEmail == "" {
+			return ErrTransferEmailRequired
+		}
+		if err := database.GetDB().Where("email = ? AND is_active = ?", req.TransferToEmail, true).First(&recipient).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTransferRecipientNotFound
+			}
+			return ErrDatabase.Wrap(err)
+		}
+		if recipient.ID == user.ID {
+			return ErrTransferToSelf
+		}
+	}
+
+	avatarID := user.AvatarID
+	var deletedImageIDs []string
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		switch req.BlogAction {
+		case models.BlogActionTransfer:
+			if err := tx.Model(&models.Blog{}).Where("user_id = ?", user.ID).Update("user_id", recipient.ID).Error; err != nil {
+				return err
+			}
+		case models.BlogActionAnonymize:
+			deletedUser, err := deletedUserAccount(tx)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Blog{}).Where("user_id = ?", user.ID).Update("user_id", deletedUser.ID).Error; err != nil {
+				return err
+			}
+		case models.BlogActionCascade:
+			var blogs []models.Blog
+			if err := tx.Where("user_id = ?", user.ID).Find(&blogs).Error; err != nil {
+				return err
+			}
+			for _, blog := range blogs {
+				if blog.ImageID != "" {
+					deletedImageIDs = append(deletedImageIDs, blog.ImageID)
+				}
+			}
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.Blog{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Personal data is scrubbed in every mode; the placeholder email also
+		// frees the address for a future registration
+		updates := map[string]interface{}{
+			"email":       fmt.Sprintf("deleted-%d@deleted.invalid", user.ID),
+			"first_name":  "Deleted",
+			"last_name":   "User",
+			"avatar_url":  "",
+			"avatar_id":   "",
+			"is_active":   false,
+			"mfa_enabled": false,
+			"totp_secret": "",
+		}
+		if err := tx.Model(user).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserIdentity{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(user).Error
+	})
+	if err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+
+	if err := s.authService.revokeAllSessions(user.ID); err != nil {
+		log.Printf("Failed to revoke sessions for deleted user %d: %v", user.ID, err)
+	}
+
+	if avatarID != "" {
+		_ = s.cloudinary.DeleteImage(avatarID)
+	}
+	for _, imageID := range deletedImageIDs {
+		_ = s.cloudinary.DeleteImage(imageID)
+	}
+
+	return nil
+}
+
+// recentSignInWindow is how fresh a session must be to stand in for a password
+const recentSignInWindow = 10 * time.Minute
+
+// confirmRecentSignIn checks that the session was started, by a full sign-in,
+// within recentSignInWindow. Refreshing a session does not count.
+func confirmRecentSignIn(userID uint, sessionID string) error {
+	var session models.Session
+	if err := database.GetDB().
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRecentSignInRequired
+		}
+		return ErrDatabase.Wrap(err)
+	}
+	if time.Since(session.CreatedAt) > recentSignInWindow {
+		return ErrRecentSignInRequired
+	}
+	return nil
+}
+
+// deletedUserEmail identifies the placeholder account that anonymized blogs
+// are credited to. It has no password and is never active.
+const deletedUserEmail = "deleted-user@deleted.invalid"
+
+// deletedUserAccount returns the placeholder author, creating it on first use
+func deletedUserAccount(tx *gorm.DB) (*models.User, error) {
+	user := models.User{
+		Email:     deletedUserEmail,
+		FirstName: "Deleted",
+		LastName:  "User",
+		Role:      models.RoleAuthor,
+	}
+	created := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "email"}}, DoNothing: true}).Create(&user)
+	if created.Error != nil {
+		return nil, created.Error
+	}
+
+	if created.RowsAffected == 0 {
+		if err := tx.Unscoped().Where("email = ?", deletedUserEmail).First(&user).Error; err != nil {
+			return nil, err
+		}
+		return &user, nil
+	}
+
+	// Inserting false would fall back to the column default
+	if err := tx.Model(&user).UpdateColumn("is_active", false).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/utils"
+
+	"gorm.io/gorm"
+)
+
+func newTestUserService(t *testing.T) *UserService {
+	auth := newTestAuthService(t)
+	return &UserService{cfg: auth.cfg, authService: auth}
+}
+
+// startTestSession records a session for the user that began at startedAt
+func startTestSession(t *testing.T, db *gorm.DB, user *models.User, startedAt time.Time) string {
+	t.Helper()
+
+	sessionID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := createSession(db, user.ID, sessionID, ClientMeta{}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&models.Session{}).Where("id = ?", sessionID).UpdateColumn("created_at", startedAt).Error; err != nil {
+		t.Fatal(err)
+	}
+	return sessionID
+}
+
+func TestDeleteAccountConfirmation(t *testing.T) {
+	db := testDB(t)
+	service := newTestUserService(t)
+	anonymize := func(password string) models.DeleteAccountRequest {
+		return models.DeleteAccountRequest{Password: password, BlogAction: models.BlogActionAnonymize}
+	}
+
+	withPassword := createTestUser(t, db, "password@example.com")
+	hash, err := utils.HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(withPassword).Update("password", hash).Error; err != nil {
+		t.Fatal(err)
+	}
+	session := startTestSession(t, db, withPassword, time.Now())
+	if err := service.DeleteAccount(withPassword.ID, session, anonymize("wrong")); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("wrong password: DeleteAccount = %v, want %v", err, ErrInvalidPassword)
+	}
+	if err := service.DeleteAccount(withPassword.ID, session, anonymize("correct horse battery staple")); err != nil {
+		t.Errorf("right password: DeleteAccount = %v", err)
+	}
+
+	// Provider-only accounts confirm with a fresh sign-in
+	providerOnly := createTestUser(t, db, "oidc@example.com")
+	if err := db.Model(providerOnly).Update("password", "").Error; err != nil {
+		t.Fatal(err)
+	}
+	stale := startTestSession(t, db, providerOnly, time.Now().Add(-time.Hour))
+	if err := service.DeleteAccount(providerOnly.ID, stale, anonymize("")); !errors.Is(err, ErrRecentSignInRequired) {
+		t.Errorf("old session: DeleteAccount = %v, want %v", err, ErrRecentSignInRequired)
+	}
+	if err := service.DeleteAccount(providerOnly.ID, "", anonymize("")); !errors.Is(err, ErrRecentSignInRequired) {
+		t.Errorf("no session: DeleteAccount = %v, want %v", err, ErrRecentSignInRequired)
+	}
+	fresh := startTestSession(t, db, providerOnly, time.Now())
+	if err := service.DeleteAccount(providerOnly.ID, fresh, anonymize("")); err != nil {
+		t.Errorf("fresh session: DeleteAccount = %v", err)
+	}
+}
+
+func TestDeleteAccountAnonymizesBlogs(t *testing.T) {
+	db := testDB(t)
+	service := newTestUserService(t)
+
+	var blogIDs []uint
+	for _, email := range []string{"first@example.com", "second@example.com"} {
+		user := createTestUser(t, db, email)
+		if err := db.Model(user).Update("password", "").Error; err != nil {
+			t.Fatal(err)
+		}
+		blog := createTestBlog(t, user, models.CreateBlogRequest{Title: "By " + email, Published: true})
+		blogIDs = append(blogIDs, blog.ID)
+
+		session := startTestSession(t, db, user, time.Now())
+		if err := service.DeleteAccount(user.ID, session, models.DeleteAccountRequest{BlogAction: models.BlogActionAnonymize}); err != nil {
+			t.Fatalf("DeleteAccount %s: %v", email, err)
+		}
+	}
+
+	// Both end up with the one placeholder author, who can never sign in
+	for _, id := range blogIDs {
+		blog, err := newTestBlogService().GetBlogById(Actor{}, id)
+		if err != nil {
+			t.Fatalf("GetBlogById: %v", err)
+		}
+		if blog.User.Email != deletedUserEmail || blog.User.FirstName != "Deleted" || blog.User.IsActive {
+			t.Errorf("blog %d author = %s %q active %v, want the inactive deleted user", id, blog.User.Email, blog.User.FirstName, blog.User.IsActive)
+		}
+	}
+
+	var placeholders int64
+	if err := db.Model(&models.User{}).Where("email = ?", deletedUserEmail).Count(&placeholders).Error; err != nil {
+		t.Fatal(err)
+	}
+	if placeholders != 1 {
+		t.Errorf("%d deleted user accounts, want 1", placeholders)
+	}
+}
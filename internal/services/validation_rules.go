@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/validation"
+)
+
+// RegisterValidationRules adds the validate tag rules that depend on
+// configuration or the database: strong_password and unique_email
+func RegisterValidationRules(cfg *config.Config) {
+	validation.RegisterRule("strong_password", func(field validation.Field) []validation.Violation {
+		if field.Value.Kind() != reflect.String {
+			return validation.Fail("invalid", "")
+		}
+
+		// Rules that need the account, like personal_info, are applied again by the service
+		err := getPasswordPolicy(cfg).Validate(field.Name, field.Value.String(), nil)
+		var policyErr *PasswordPolicyError
+		if !errors.As(err, &policyErr) {
+			return nil
+		}
+
+		violations := make([]validation.Violation, 0, len(policyErr.Violations))
+		for _, v := range policyErr.Violations {
+			violations = append(violations, validation.Violation{Code: v.Code, Param: passwordRuleParam(cfg, v.Code)})
+		}
+		return violations
+	})
+
+	validation.RegisterRule("unique_email", func(field validation.Field) []validation.Violation {
+		if field.Value.Kind() != reflect.String {
+			return validation.Fail("invalid", "")
+		}
+
+		// Deleted accounts keep a scrubbed address, so they are counted too
+		var count int64
+		email := strings.ToLower(strings.TrimSpace(field.Value.String()))
+		if err := database.GetDB().Unscoped().Model(&models.User{}).Where("LOWER(email) = ?", email).Count(&count).Error; err != nil {
+			// The unique index still protects the table, let the service report it
+			log.Printf("Failed to check email uniqueness: %v", err)
+			return nil
+		}
+		if count > 0 {
+			return validation.Fail("unique_email", "")
+		}
+		return nil
+	})
+}
+
+func passwordRuleParam(cfg *config.Config, code string) string {
+	switch code {
+	case "min_length":
+		return strconv.Itoa(cfg.PasswordMinLength)
+	case "max_length":
+		return strconv.Itoa(maxPasswordBytes)
+	case "char_classes":
+		return strconv.Itoa(cfg.PasswordMinCharClasses)
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const DefaultLocale = "en"
+
+// catalog holds the message templates per locale. {field} and {param} are
+// replaced with the field name and the rule parameter. min and max look up
+// a variant for the kind of value first, e.g. "min.string".
+var catalog = map[string]map[string]string{
+	"en": {
+		"validation_failed": "Validation failed",
+		"invalid":           "{field} is invalid",
+		"required":          "{field} is required",
+		"email":             "{field} must be a valid email address",
+		"min.string":        "{field} must be at least {param} characters long",
+		"min.items":         "{field} must contain at least {param} items",
+		"min.number":        "{field} must be at least {param}",
+		"max.string":        "{field} must be at most {param} characters long",
+		"max.items":         "{field} must contain at most {param} items",
+		"max.number":        "{field} must be at most {param}",
+		"oneof":             "{field} must be one of: {param}",
+		"slug":              "{field} may only contain lowercase letters, digits and single dashes",
+		"date":              "{field} must be a date (YYYY-MM-DD) or an RFC 3339 timestamp",
+		"unique_email":      "{field} is already registered",
+		"min_length":        "{field} must be at least {param} characters long",
+		"max_length":        "{field} must be at most {param} bytes long",
+		"uppercase":         "{field} must contain an uppercase letter",
+		"lowercase":         "{field} must contain a lowercase letter",
+		"digit":             "{field} must contain a digit",
+		"symbol":            "{field} must contain a symbol",
+		"char_classes":      "{field} must contain at least {param} of: uppercase letters, lowercase letters, digits, symbols",
+		"personal_info":     "{field} must not contain your email address or name",
+		"breached":          "{field} has appeared in a data breach, please choose a different one",
+	},
+	"id": {
+		"validation_failed": "Validasi gagal",
+		"invalid":           "{field} tidak valid",
+		"required":          "{field} wajib diisi",
+		"email":             "{field} harus berupa alamat email yang valid",
+		"min.string":        "{field} minimal {param} karakter",
+		"min.items":         "{field} minimal berisi {param} item",
+		"min.number":        "{field} minimal {param}",
+		"max.string":        "{field} maksimal {param} karakter",
+		"max.items":         "{field} maksimal berisi {param} item",
+		"max.number":        "{field} maksimal {param}",
+		"oneof":             "{field} harus salah satu dari: {param}",
+		"slug":              "{field} hanya boleh berisi huruf kecil, angka dan tanda hubung tunggal",
+		"date":              "{field} harus berupa tanggal (YYYY-MM-DD) atau timestamp RFC 3339",
+		"unique_email":      "{field} sudah terdaftar",
+		"min_length":        "{field} minimal {param} karakter",
+		"max_length":        "{field} maksimal {param} byte",
+		"uppercase":         "{field} harus mengandung huruf besar",
+		"lowercase":         "{field} harus mengandung huruf kecil",
+		"digit":             "{field} harus mengandung angka",
+		"symbol":            "{field} harus mengandung simbol",
+		"char_classes":      "{field} harus mengandung minimal {param} dari: huruf besar, huruf kecil, angka, simbol",
+		"personal_info":     "{field} tidak boleh mengandung email atau nama Anda",
+		"breached":          "{field} pernah muncul dalam kebocoran data, silakan pilih yang lain",
+	},
+}
+
+// Locale picks the best supported locale from an Accept-Language header
+func Locale(acceptLanguage string) string {
+	best, bestQuality := DefaultLocale, -1.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, quality := parseLanguage(part)
+		if _, ok := catalog[tag]; ok && quality > 0 && quality > bestQuality {
+			best, bestQuality = tag, quality
+		}
+	}
+	return best
+}
+
+// Message returns the catalog entry for key in locale, e.g. the
+// "validation_failed" summary that goes next to the field list
+func Message(locale, key string) string {
+	if text, ok := catalog[locale][key]; ok {
+		return text
+	}
+	return catalog[DefaultLocale][key]
+}
+
+func message(locale string, violation Violation, field string, value reflect.Value) string {
+	text := ""
+	for _, key := range []string{violation.Code + "." + kindName(value), violation.Code, "invalid"} {
+		if text = Message(locale, key); text != "" {
+			break
+		}
+	}
+
+	return strings.NewReplacer("{field}", field, "{param}", violation.Param).Replace(text)
+}
+
+func parseLanguage(part string) (string, float64) {
+	tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+	tag, _, _ = strings.Cut(strings.ToLower(tag), "-")
+
+	quality := 1.0
+	if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+		quality, _ = strconv.ParseFloat(q, 64)
+	}
+	return tag, quality
+}
+
+func kindName(value reflect.Value) string {
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return "items"
+	}
+	return "number"
+}
@@ -0,0 +1,154 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go-fiber-boilerplate/utils"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// paramChecks validate the parameter of the rules that take one when a
+// struct's tags are first parsed
+var paramChecks = map[string]func(t reflect.Type, param string) error{
+	"min": checkLimit,
+	"max": checkLimit,
+}
+
+func init() {
+	RegisterRule("required", required)
+	RegisterRule("email", email)
+	RegisterRule("min", minRule)
+	RegisterRule("max", maxRule)
+	RegisterRule("oneof", oneOf)
+	RegisterRule("slug", slugRule)
+	RegisterRule("date", dateRule)
+}
+
+// Fail is a shorthand for rules that report a single violation
+func Fail(code, param string) []Violation {
+	return []Violation{{Code: code, Param: param}}
+}
+
+func required(field Field) []Violation {
+	if isEmpty(field.Value) {
+		return Fail("required", "")
+	}
+	return nil
+}
+
+func email(field Field) []Violation {
+	if field.Value.Kind() != reflect.String || !utils.ValidateEmail(field.Value.String()) {
+		return Fail("email", "")
+	}
+	return nil
+}
+
+// minRule and maxRule can rely on checkLimit having accepted the parameter
+// and the field type
+func minRule(field Field) []Violation {
+	size, _ := measure(field.Value)
+	limit, _ := strconv.ParseFloat(field.Param, 64)
+	if size < limit {
+		return Fail("min", field.Param)
+	}
+	return nil
+}
+
+func maxRule(field Field) []Violation {
+	size, _ := measure(field.Value)
+	limit, _ := strconv.ParseFloat(field.Param, 64)
+	if size > limit {
+		return Fail("max", field.Param)
+	}
+	return nil
+}
+
+func oneOf(field Field) []Violation {
+	value := field.Value
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return Fail("oneof", field.Param)
+		}
+		value = value.Elem()
+	}
+
+	actual := fmt.Sprint(value.Interface())
+	for _, allowed := range strings.Fields(field.Param) {
+		if actual == allowed {
+			return nil
+		}
+	}
+	return Fail("oneof", strings.Join(strings.Fields(field.Param), ", "))
+}
+
+func slugRule(field Field) []Violation {
+	if field.Value.Kind() != reflect.String || !slugPattern.MatchString(field.Value.String()) {
+		return Fail("slug", "")
+	}
+	return nil
+}
+
+func dateRule(field Field) []Violation {
+	if field.Value.Kind() != reflect.String {
+		return Fail("date", "")
+	}
+	if _, _, err := utils.ParseDate(field.Value.String()); err != nil {
+		return Fail("date", "")
+	}
+	return nil
+}
+
+func checkLimit(t reflect.Type, param string) error {
+	if _, err := strconv.ParseFloat(param, 64); err != nil {
+		return fmt.Errorf("%q is not a number", param)
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !measurable(t.Kind()) {
+		return fmt.Errorf("%s has no length or size to compare", t)
+	}
+	return nil
+}
+
+func measurable(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// measure is the length of strings (in characters) and collections, or the
+// value of numbers, which is what min and max compare against
+func measure(value reflect.Value) (float64, bool) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return 0, true
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
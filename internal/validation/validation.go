@@ -0,0 +1,200 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go-fiber-boilerplate/internal/models"
+)
+
+// Errors is the per-field result of a failed validation
+type Errors []models.FieldError
+
+func (e Errors) Error() string {
+	return "validation failed"
+}
+
+// Violation is a single broken rule. Code selects the message from the
+// catalog and Param is substituted into it, e.g. the 8 of min=8.
+type Violation struct {
+	Code  string
+	Param string
+}
+
+// Field is what a rule gets to look at
+type Field struct {
+	Name  string
+	Value reflect.Value
+	Param string
+}
+
+// Rule checks a field and returns the violations it found, if any
+type Rule func(field Field) []Violation
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]Rule{}
+
+	fieldCache sync.Map // reflect.Type -> typeSpec
+)
+
+// RegisterRule makes name usable in validate tags. Registering a name twice
+// replaces the earlier rule.
+func RegisterRule(name string, rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = rule
+}
+
+func lookupRule(name string) (Rule, bool) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	rule, ok := rules[name]
+	return rule, ok
+}
+
+type tagRule struct {
+	name  string
+	param string
+}
+
+type fieldSpec struct {
+	index     int
+	name      string
+	omitEmpty bool
+	rules     []tagRule
+}
+
+// typeSpec is the parsed form of a struct's validate tags, or the reason
+// they could not be used
+type typeSpec struct {
+	fields []fieldSpec
+	err    error
+}
+
+// Struct enforces the validate tags of the struct v points to. Rules on a
+// field run in order and stop at the first one that fails. Messages are
+// written in locale, see Locale. It returns Errors when any field fails,
+// and a plain error when the tags themselves are wrong, e.g. an unknown
+// rule or min=abc.
+func Struct(v interface{}, locale string) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	parsed := fieldsOf(value.Type())
+	if parsed.err != nil {
+		return parsed.err
+	}
+
+	var errs Errors
+	for _, spec := range parsed.fields {
+		fieldValue := value.Field(spec.index)
+		if spec.omitEmpty && isEmpty(fieldValue) {
+			continue
+		}
+
+		for _, tr := range spec.rules {
+			rule, ok := lookupRule(tr.name)
+			if !ok {
+				return fmt.Errorf("validation: unknown rule %q on %s.%s", tr.name, value.Type(), value.Type().Field(spec.index).Name)
+			}
+
+			violations := rule(Field{Name: spec.name, Value: fieldValue, Param: tr.param})
+			if len(violations) == 0 {
+				continue
+			}
+
+			for _, violation := range violations {
+				errs = append(errs, models.FieldError{
+					Field:   spec.name,
+					Code:    violation.Code,
+					Message: message(locale, violation, spec.name, fieldValue),
+				})
+			}
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func fieldsOf(t reflect.Type) typeSpec {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(typeSpec)
+	}
+
+	parsed := typeSpec{}
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		tag := structField.Tag.Get("validate")
+		if tag == "" || tag == "-" || !structField.IsExported() {
+			continue
+		}
+
+		spec := fieldSpec{index: i, name: fieldName(structField)}
+		for _, part := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch name {
+			case "":
+			case "omitempty":
+				spec.omitEmpty = true
+			default:
+				if err := checkTag(structField, name, param); err != nil && parsed.err == nil {
+					parsed.err = fmt.Errorf("validation: %s.%s: %w", t, structField.Name, err)
+				}
+				spec.rules = append(spec.rules, tagRule{name: name, param: param})
+			}
+		}
+		parsed.fields = append(parsed.fields, spec)
+	}
+
+	fieldCache.Store(t, parsed)
+	return parsed
+}
+
+// checkTag catches a mistyped rule or parameter once per struct type, so it
+// is reported as an error instead of failing midway through a request
+func checkTag(field reflect.StructField, name, param string) error {
+	if _, ok := lookupRule(name); !ok {
+		return fmt.Errorf("unknown rule %q", name)
+	}
+	if check, ok := paramChecks[name]; ok {
+		return check(field.Type, param)
+	}
+	return nil
+}
+
+// fieldName reports a field under the name clients send it as
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "query"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func isEmpty(value reflect.Value) bool {
+	if value.Kind() == reflect.String {
+		return strings.TrimSpace(value.String()) == ""
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
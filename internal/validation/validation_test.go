@@ -0,0 +1,107 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type signupForm struct {
+	Name  string   `json:"name" validate:"required,max=5"`
+	Email string   `json:"email" validate:"omitempty,email"`
+	Tags  []string `json:"tags" validate:"max=2"`
+	Age   *int     `json:"age" validate:"omitempty,min=18"`
+}
+
+func TestStructReportsFieldErrors(t *testing.T) {
+	age := 12
+	err := Struct(&signupForm{Name: "Johnny", Tags: []string{"a", "b", "c"}, Age: &age}, "en")
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Struct = %v, want Errors", err)
+	}
+
+	want := map[string]string{
+		"name": "name must be at most 5 characters long",
+		"tags": "tags must contain at most 2 items",
+		"age":  "age must be at least 18",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d field errors, want %d: %+v", len(errs), len(want), errs)
+	}
+	for _, fieldErr := range errs {
+		if fieldErr.Message != want[fieldErr.Field] {
+			t.Errorf("%s: message %q, want %q", fieldErr.Field, fieldErr.Message, want[fieldErr.Field])
+		}
+	}
+}
+
+func TestStructPasses(t *testing.T) {
+	if err := Struct(&signupForm{Name: "John", Email: "john@example.com"}, "en"); err != nil {
+		t.Fatalf("Struct = %v, want nil", err)
+	}
+}
+
+func TestStructLocalizesMessages(t *testing.T) {
+	err := Struct(&signupForm{}, Locale("id-ID,en;q=0.5"))
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("Struct = %v, want one field error", err)
+	}
+	if errs[0].Message != "name wajib diisi" {
+		t.Errorf("message %q, want %q", errs[0].Message, "name wajib diisi")
+	}
+}
+
+func TestStructRejectsBadTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{
+			name: "unknown rule",
+			value: &struct {
+				Name string `validate:"requird"`
+			}{},
+			want: `unknown rule "requird"`,
+		},
+		{
+			name: "malformed param",
+			value: &struct {
+				Name string `validate:"max=ten"`
+			}{Name: "x"},
+			want: `"ten" is not a number`,
+		},
+		{
+			name: "param on a type without a size",
+			value: &struct {
+				Active bool `validate:"min=1"`
+			}{},
+			want: "bool has no length or size to compare",
+		},
+	}
+
+	for _, tt := range tests {
+		var err error
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("%s: Struct panicked: %v", tt.name, r)
+				}
+			}()
+			err = Struct(tt.value, "en")
+		}()
+
+		var errs Errors
+		if err == nil || errors.As(err, &errs) {
+			t.Errorf("%s: Struct = %v, want a tag error", tt.name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %q does not mention %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package response
+
+import "net/http"
+
+// ProblemContentType is the media type of RFC 7807 error responses
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix turns an error code into the problem type URI
+const problemTypePrefix = "urn:blog-app-api:problem:"
+
+// Problem is an RFC 7807 problem details object. Code is a stable, machine
+// readable identifier that clients can switch on instead of parsing Detail.
+type Problem struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail,omitempty"`
+	Instance string      `json:"instance,omitempty"`
+	Code     string      `json:"code"`
+	Fields   interface{} `json:"fields,omitempty"`
+}
+
+func NewProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   problemTypePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
@@ -1,10 +1,16 @@
 package response
 
 type APIResponse struct {
-    Success bool        `json:"success"`
-    Message string      `json:"message"`
-    Data    interface{} `json:"data,omitempty"`
-    Error   string      `json:"error,omitempty"`
+    Success    bool        `json:"success"`
+    Message    string      `json:"message,omitempty"`
+    Data       interface{} `json:"data,omitempty"`
+    Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+    Page  int   `json:"page"`
+    Limit int   `json:"limit"`
+    Total int64 `json:"total"`
 }
 
 func Success(message string, data interface{}) APIResponse {
@@ -15,10 +21,14 @@ func Success(message string, data interface{}) APIResponse {
     }
 }
 
-func Error(message, error string) APIResponse {
+func Paginated(data interface{}, page, limit int, total int64) APIResponse {
     return APIResponse{
-        Success: false,
-        Message: message,
-        Error:   error,
+        Success: true,
+        Data:    data,
+        Pagination: &Pagination{
+            Page:  page,
+            Limit: limit,
+            Total: total,
+        },
     }
-}
\ No newline at end of file
+}
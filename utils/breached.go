@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed data/breached_passwords.txt
+var bundledBreachedPasswords string
+
+// BreachedPasswordList answers whether a password appears in a list of
+// breached password SHA-1 hashes. Lookups follow the k-anonymity range
+// scheme: the first 5 hex characters of the hash select a bucket and only
+// the remaining suffixes are compared, so a directory of range files as
+// published by Have I Been Pwned can be used without loading it in memory.
+type BreachedPasswordList struct {
+	buckets  map[string]map[string]struct{}
+	rangeDir string
+}
+
+// LoadBreachedPasswordList loads the bundled list of common passwords plus an
+// optional local source at path. path may be a file with one SHA-1 hash per
+// line (optionally followed by ":count") or a directory of range files named
+// after the 5 character prefix, each containing "SUFFIX:COUNT" lines.
+func LoadBreachedPasswordList(path string) (*BreachedPasswordList, error) {
+	list := &BreachedPasswordList{buckets: make(map[string]map[string]struct{})}
+
+	if err := list.addHashes(strings.NewReader(bundledBreachedPasswords)); err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		return list, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		list.rangeDir = path
+		return list, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if err := list.addHashes(file); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Contains reports whether the password's hash is on the list
+func (l *BreachedPasswordList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	if _, found := l.buckets[prefix][suffix]; found {
+		return true
+	}
+
+	if l.rangeDir == "" {
+		return false
+	}
+	return rangeFileContains(l.rangeDir, prefix, suffix)
+}
+
+func (l *BreachedPasswordList) addHashes(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if len(hash) != 40 {
+			continue
+		}
+		hash = strings.ToUpper(hash)
+
+		prefix := hash[:5]
+		if l.buckets[prefix] == nil {
+			l.buckets[prefix] = make(map[string]struct{})
+		}
+		l.buckets[prefix][hash[5:]] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+func rangeFileContains(dir, prefix, suffix string) bool {
+	for _, name := range []string{prefix, prefix + ".txt"} {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			candidate, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+			if strings.EqualFold(candidate, suffix) {
+				file.Close()
+				return true
+			}
+		}
+		file.Close()
+		return false
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestBreachedPasswordListBundled(t *testing.T) {
+	list, err := LoadBreachedPasswordList("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"password", true},
+		{"Password1", true},
+		{"qwerty123", true},
+		{"PASSWORD", false},
+		{"correct horse battery staple", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := list.Contains(tt.password); got != tt.want {
+			t.Errorf("Contains(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestBreachedPasswordListFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.txt")
+	lines := []string{
+		sha1Hex("first leaked"),
+		strings.ToLower(sha1Hex("second leaked")) + ":42",
+		"  " + sha1Hex("third leaked") + "  ",
+		"not a hash",
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := LoadBreachedPasswordList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, password := range []string{"first leaked", "second leaked", "third leaked", "password"} {
+		if !list.Contains(password) {
+			t.Errorf("Contains(%q) = false, want true", password)
+		}
+	}
+	if list.Contains("never leaked") {
+		t.Error("Contains(never leaked) = true, want false")
+	}
+
+	if _, err := LoadBreachedPasswordList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("a missing file was accepted")
+	}
+}
+
+func TestBreachedPasswordListRangeDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Range files hold only the suffix after the 5 character prefix
+	plain := sha1Hex("in a range file")
+	write(plain[:5], "0000000000000000000000000000000000A:1\r\n"+plain[5:]+":3\r\n")
+	withExt := sha1Hex("in a .txt range file")
+	write(withExt[:5]+".txt", strings.ToLower(withExt[5:])+":12\n")
+	// A suffix under the wrong prefix must not match
+	misplaced := sha1Hex("under the wrong prefix")
+	write("FFFFF", misplaced[5:]+":1\n")
+
+	list, err := LoadBreachedPasswordList(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"in a range file", true},
+		{"in a .txt range file", true},
+		{"password", true},
+		{"under the wrong prefix", misplaced[:5] == "FFFFF"},
+		{"not in any range", false},
+	}
+
+	for _, tt := range tests {
+		if got := list.Contains(tt.password); got != tt.want {
+			t.Errorf("Contains(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
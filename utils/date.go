@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"errors"
+	"time"
+)
+
+// ParseDate accepts a calendar date ("2006-01-02", server local time) or an
+// RFC 3339 timestamp. dateOnly reports which one it was, so callers can
+// treat a date as the whole day.
+func ParseDate(value string) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, errors.New("expected YYYY-MM-DD or an RFC 3339 timestamp")
+}
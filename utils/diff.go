@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Diff operations
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// maxDiffEdits bounds the work and memory of a diff, which grow with the
+// square of the number of edits. Texts that differ more than this are shown
+// as fully replaced.
+const maxDiffEdits = 2000
+
+// DiffChunk is a run of text that is unchanged, inserted or deleted
+type DiffChunk struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLines compares two texts line by line
+func DiffLines(from, to string) []DiffChunk {
+	return diffTokens(splitLines(from), splitLines(to))
+}
+
+// DiffWords compares two texts word by word. Whitespace is kept as tokens of
+// its own, so joining the chunks gives back the original texts.
+func DiffWords(from, to string) []DiffChunk {
+	return diffTokens(splitWords(from), splitWords(to))
+}
+
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func splitWords(text string) []string {
+	var tokens []string
+	start, inSpace := 0, false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if i > start && space != inSpace {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+func diffTokens(a, b []string) []DiffChunk {
+	var out []DiffChunk
+	emit := func(op, text string) {
+		if n := len(out); n > 0 && out[n-1].Op == op {
+			out[n-1].Text += text
+			return
+		}
+		out = append(out, DiffChunk{Op: op, Text: text})
+	}
+
+	// The common prefix and suffix need no search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, token := range a[:prefix] {
+		emit(DiffEqual, token)
+	}
+
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if ops, ok := myers(middleA, middleB); ok {
+		for _, op := range ops {
+			emit(op.op, op.text)
+		}
+	} else {
+		for _, token := range middleA {
+			emit(DiffDelete, token)
+		}
+		for _, token := range middleB {
+			emit(DiffInsert, token)
+		}
+	}
+
+	for _, token := range a[len(a)-suffix:] {
+		emit(DiffEqual, token)
+	}
+	return out
+}
+
+type diffOp struct {
+	op   string
+	text string
+}
+
+// myers finds a shortest edit script from a to b (Myers, "An O(ND)
+// Difference Algorithm and Its Variations"). It gives up once more than
+// maxDiffEdits edits are needed.
+func myers(a, b []string) ([]diffOp, bool) {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit > maxDiffEdits {
+		limit = maxDiffEdits
+	}
+
+	// v[k+offset] is the furthest x reached on diagonal k. trace keeps a copy
+	// of the diagonals -d..d before each round, for walking back afterwards.
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace), true
+			}
+		}
+	}
+	return nil, false
+}
+
+func backtrack(a, b []string, trace [][]int) []diffOp {
+	var ops []diffOp
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{DiffEqual, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			ops = append(ops, diffOp{DiffInsert, b[y-1]})
+		} else {
+			ops = append(ops, diffOp{DiffDelete, a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	// What is left is the snake both texts start with
+	for x > 0 {
+		ops = append(ops, diffOp{DiffEqual, a[x-1]})
+		x--
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     []DiffChunk
+	}{
+		{"both empty", "", "", nil},
+		{"unchanged", "a\nb\n", "a\nb\n", []DiffChunk{{DiffEqual, "a\nb\n"}}},
+		{"added to empty", "", "a\n", []DiffChunk{{DiffInsert, "a\n"}}},
+		{"cleared", "a\n", "", []DiffChunk{{DiffDelete, "a\n"}}},
+		{
+			"line changed in the middle",
+			"one\ntwo\nthree\n", "one\n2\nthree\n",
+			[]DiffChunk{{DiffEqual, "one\n"}, {DiffDelete, "two\n"}, {DiffInsert, "2\n"}, {DiffEqual, "three\n"}},
+		},
+		{
+			"line moved",
+			"a\nb\nc\n", "b\nc\na\n",
+			[]DiffChunk{{DiffDelete, "a\n"}, {DiffEqual, "b\nc\n"}, {DiffInsert, "a\n"}},
+		},
+		{
+			"missing final newline is a change",
+			"a\nb", "a\nb\n",
+			[]DiffChunk{{DiffEqual, "a\n"}, {DiffDelete, "b"}, {DiffInsert, "b\n"}},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := DiffLines(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: DiffLines = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	got := DiffWords("the quick brown fox", "the slow brown  fox")
+	want := []DiffChunk{
+		{DiffEqual, "the "},
+		{DiffDelete, "quick"},
+		{DiffInsert, "slow"},
+		{DiffEqual, " brown"},
+		{DiffDelete, " "},
+		{DiffInsert, "  "},
+		{DiffEqual, "fox"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffWords = %q, want %q", got, want)
+	}
+}
+
+// TestDiffIsMinimal checks on random texts that the chunks rebuild both
+// sides and that no shorter edit script exists
+func TestDiffIsMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		words := make([]string, rng.Intn(12))
+		for i := range words {
+			words[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return strings.Join(words, "\n")
+	}
+
+	for i := 0; i < 500; i++ {
+		from, to := randomText(), randomText()
+		chunks := DiffLines(from, to)
+
+		checkDiffRebuilds(t, from, to, chunks)
+
+		edits := 0
+		for _, chunk := range chunks {
+			if chunk.Op != DiffEqual {
+				edits += len(splitLines(chunk.Text))
+			}
+		}
+		a, b := splitLines(from), splitLines(to)
+		if want := len(a) + len(b) - 2*lcsLength(a, b); edits != want {
+			t.Fatalf("DiffLines(%q, %q) makes %d edits, want %d", from, to, edits, want)
+		}
+	}
+}
+
+func TestDiffFallsBackBeyondMaxEdits(t *testing.T) {
+	var from, to strings.Builder
+	from.WriteString("header\n")
+	to.WriteString("header\n")
+	for i := 0; i < maxDiffEdits; i++ {
+		fmt.Fprintf(&from, "old %d\n", i)
+		fmt.Fprintf(&to, "new %d\n", i)
+	}
+	from.WriteString("footer\n")
+	to.WriteString("footer\n")
+
+	chunks := DiffLines(from.String(), to.String())
+	checkDiffRebuilds(t, from.String(), to.String(), chunks)
+
+	ops := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		ops[i] = chunk.Op
+	}
+	if want := []string{DiffEqual, DiffDelete, DiffInsert, DiffEqual}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("ops = %v, want %v", ops, want)
+	}
+}
+
+func checkDiffRebuilds(t *testing.T, from, to string, chunks []DiffChunk) {
+	t.Helper()
+
+	var gotFrom, gotTo strings.Builder
+	for i, chunk := range chunks {
+		if chunk.Text == "" {
+			t.Fatalf("chunk %d is empty", i)
+		}
+		if i > 0 && chunks[i-1].Op == chunk.Op {
+			t.Fatalf("chunks %d and %d are both %s", i-1, i, chunk.Op)
+		}
+		if chunk.Op != DiffInsert {
+			gotFrom.WriteString(chunk.Text)
+		}
+		if chunk.Op != DiffDelete {
+			gotTo.WriteString(chunk.Text)
+		}
+	}
+	if gotFrom.String() != from || gotTo.String() != to {
+		t.Fatalf("chunks rebuild %q -> %q, want %q -> %q", gotFrom.String(), gotTo.String(), from, to)
+	}
+}
+
+func lcsLength(a, b []string) int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] > lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	return lengths[0][0]
+}
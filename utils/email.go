@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"net/mail"
 	"net/smtp"
 	"strings"
 )
@@ -108,6 +109,76 @@ func GenerateResetPasswordEmail(resetLink string) string {
 </html>`
 }
 
+func GenerateVerifyEmailEmail(verifyLink string) string {
+	return `<html lang="en" style="margin:0;padding:0;">
+  <body style="margin:0;padding:0;background:#f6f9fc;">
+    <!-- Preheader (preview text) -->
+    <div style="display:none;max-height:0;overflow:hidden;opacity:0;">
+      Confirm your email address to finish setting up your account.
+    </div>
+
+    <table role="presentation" width="100%" cellspacing="0" cellpadding="0" border="0" style="background:#f6f9fc;padding:24px 0;">
+      <tr>
+        <td align="center">
+          <table role="presentation" width="600" cellspacing="0" cellpadding="0" border="0" style="width:600px;max-width:100%;background:#ffffff;border-radius:12px;box-shadow:0 4px 16px rgba(0,0,0,0.05);overflow:hidden;">
+            <!-- Header -->
+            <tr>
+              <td style="padding:20px 28px;background:#111827;">
+                <h1 style="margin:0;font-family:Segoe UI,Roboto,Helvetica Neue,Arial,sans-serif;font-size:18px;line-height:24px;color:#ffffff;">
+                  APP_NAME
+                </h1>
+              </td>
+            </tr>
+
+            <!-- Body -->
+            <tr>
+              <td style="padding:28px;">
+                <h2 style="margin:0 0 8px 0;font-family:Segoe UI,Roboto,Helvetica Neue,Arial,sans-serif;font-size:22px;line-height:30px;color:#111827;">
+                  Verify Your Email
+                </h2>
+                <p style="margin:0 0 16px 0;font-family:Segoe UI,Roboto,Helvetica Neue,Arial,sans-serif;font-size:14px;line-height:22px;color:#374151;">
+                  Thanks for signing up for APP_NAME. Click the button below to confirm that this email address belongs to you.
+                </p>
+
+                <!-- Button -->
+                <table role="presentation" cellspacing="0" cellpadding="0" border="0" style="margin:0 0 16px 0;">
+                  <tr>
+                    <td>
+                      <a href="` + verifyLink + `" target="_blank"
+                         style="display:inline-block;background:#4F46E5;color:#ffffff;text-decoration:none;font-family:Segoe UI,Roboto,Helvetica Neue,Arial,sans-serif;font-size:14px;line-height:20px;font-weight:600;padding:12px 20px;border-radius:8px;">
+                        Verify Email
+                      </a>
+                    </td>
+                  </tr>
+                </table>
+
+                <p style="margin:0 0 8px 0;font-family:Segoe UI,Roboto,Helvetica Neue,Arial,sans-serif;font-size:12px;line-height:20px;color:#6B7280;">
+                  This link will expire in <strong>24 hours</strong>.
+                </p>
+                <p style="margin:0 0 0 0;font-family:Segoe UI,Roboto,Helvetica Neue,Arial,sans-serif;font-size:12px;line-height:20px;color:#6B7280;">
+                  If you did not create an account, you can safely ignore this email.
+                </p>
+              </td>
+            </tr>
+
+            <!-- Footer -->
+            <tr>
+              <td style="padding:16px 28px;background:#F3F4F6;">
+                <p style="margin:0;font-family:Segoe UI,Roboto,Helvetica Neue,Arial,sans-serif;font-size:11px;line-height:18px;color:#6B7280;">
+                  © 2025 APP_NAME • This is an automated message. Please do not reply. 
+                  For assistance, contact support at <a href="mailto:support@yourapp.com" style="color:#4F46E5;text-decoration:underline;">support@yourapp.com</a>.
+                </p>
+              </td>
+            </tr>
+
+          </table>
+        </td>
+      </tr>
+    </table>
+  </body>
+</html>`
+}
+
 func GeneratePasswordResetSuccessEmail() string {
 	return `
 		<html>
@@ -120,6 +191,49 @@ func GeneratePasswordResetSuccessEmail() string {
 	`
 }
 
+func GenerateMagicLinkEmail(magicLink string, expiresInMinutes int) string {
+	return `
+		<html>
+		<body>
+			<h2>Sign In to Your Account</h2>
+			<p>We received a request to sign in to your account without a password.</p>
+			<p>Use the link below to sign in. The link can only be used once and expires in ` + fmt.Sprintf("%d minutes", expiresInMinutes) + `.</p>
+			<p><a href="` + magicLink + `">Sign In</a></p>
+			<p>If you did not request this, you can safely ignore this email.</p>
+		</body>
+		</html>
+	`
+}
+
+func GenerateUnlockAccountEmail(unlockLink string) string {
+	return `
+		<html>
+		<body>
+			<h2>Your Account Has Been Locked</h2>
+			<p>We noticed several failed sign-in attempts on your account, so we have temporarily locked it.</p>
+			<p>If this was you, you can unlock your account right away using the link below. The link expires in 1 hour.</p>
+			<p><a href="` + unlockLink + `">Unlock My Account</a></p>
+			<p>If this was not you, we recommend resetting your password.</p>
+		</body>
+		</html>
+	`
+}
+
+// ValidateEmail accepts a bare RFC 5322 address (no display name) whose
+// domain has at least one dot, e.g. "jane@example.com"
 func ValidateEmail(email string) bool {
-	return strings.Contains(email, "@") && strings.Contains(email, ".")
+	if email == "" || len(email) > 254 {
+		return false
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Name != "" || address.Address != email {
+		return false
+	}
+
+	_, domain, _ := strings.Cut(email, "@")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return false
+	}
+	return true
 }
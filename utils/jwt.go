@@ -1,37 +1,76 @@
 package utils
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Tokens carry their timestamps to the microsecond, so one issued right after
+// a logout-all can be told apart from one issued just before it
+func init() {
+	jwt.TimePrecision = time.Microsecond
+}
+
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID uint, email, secret string) (string, error) {
+// JWTConfig holds what is needed to sign and verify access tokens
+type JWTConfig struct {
+	Keys     *KeySet
+	Issuer   string
+	Audience string
+}
+
+func GenerateJWT(userID uint, email, role, sessionID string, jwtConfig JWTConfig, ttl time.Duration) (string, error) {
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        jti,
+			Issuer:    jwtConfig.Issuer,
+			Audience:  jwt.ClaimStrings{jwtConfig.Audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	token := jwt.NewWithClaims(jwtConfig.Keys.signingMethod(), claims)
+	token.Header["kid"] = jwtConfig.Keys.SigningKeyID()
+	return token.SignedString(jwtConfig.Keys.signingKey)
 }
 
-func ValidateJWT(tokenString, secret string) (*Claims, error) {
+// ValidateJWT verifies the signature with the key named in the kid header
+// and checks expiry, issuer and audience. Only asymmetric algorithms are
+// accepted, so a token can never be forged with a public key as HMAC secret.
+func ValidateJWT(tokenString string, jwtConfig JWTConfig) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing kid header")
+		}
+		return jwtConfig.Keys.verificationKey(kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(jwtConfig.Issuer),
+		jwt.WithAudience(jwtConfig.Audience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
@@ -43,4 +82,3 @@ func ValidateJWT(tokenString, secret string) (*Claims, error) {
 
 	return claims, nil
 }
-
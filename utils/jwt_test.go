@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestValidateJWT(t *testing.T) {
+	edKey := newEd25519Key(t)
+	rsaKey := newRSAKey(t, 2048)
+	otherKey := newEd25519Key(t)
+
+	keySet := &KeySet{
+		signingKID: "ed",
+		signingKey: edKey,
+		publicKeys: map[string]crypto.PublicKey{"ed": edKey.Public(), "rsa": rsaKey.Public()},
+	}
+	cfg := JWTConfig{Keys: keySet, Issuer: "blog-app-api", Audience: "blog-app"}
+
+	token, err := GenerateJWT(7, "ada@example.com", "author", "session", cfg, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	claims, err := ValidateJWT(token, cfg)
+	if err != nil {
+		t.Fatalf("ValidateJWT of a fresh token: %v", err)
+	}
+	if claims.UserID != 7 || claims.Email != "ada@example.com" || claims.Role != "author" || claims.SessionID != "session" || claims.ID == "" {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	// sign builds a token with valid claims, changed by edit, and the given
+	// algorithm, key and kid header
+	sign := func(method jwt.SigningMethod, key interface{}, kid string, edit func(*Claims)) string {
+		t.Helper()
+
+		now := time.Now()
+		claims := &Claims{
+			UserID: 7,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    cfg.Issuer,
+				Audience:  jwt.ClaimStrings{cfg.Audience},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			},
+		}
+		if edit != nil {
+			edit(claims)
+		}
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	edPublic := []byte(edKey.Public().(ed25519.PublicKey))
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"EdDSA", sign(jwt.SigningMethodEdDSA, edKey, "ed", nil), true},
+		{"RS256 from an older key", sign(jwt.SigningMethodRS256, rsaKey, "rsa", nil), true},
+		{"alg none", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "ed", nil), false},
+		{"HS256 keyed with the public key", sign(jwt.SigningMethodHS256, edPublic, "ed", nil), false},
+		{"RS384", sign(jwt.SigningMethodRS384, rsaKey, "rsa", nil), false},
+		{"alg not matching the kid's key", sign(jwt.SigningMethodRS256, rsaKey, "ed", nil), false},
+		{"unknown kid", sign(jwt.SigningMethodEdDSA, edKey, "gone", nil), false},
+		{"missing kid", sign(jwt.SigningMethodEdDSA, edKey, "", nil), false},
+		{"signed by another key", sign(jwt.SigningMethodEdDSA, otherKey, "ed", nil), false},
+		{"wrong issuer", sign(jwt.SigningMethodEdDSA, edKey, "ed", func(c *Claims) { c.Issuer = "someone-else" }), false},
+		{"no issuer", sign(jwt.SigningMethodEdDSA, edKey, "ed", func(c *Claims) { c.Issuer = "" }), false},
+		{"wrong audience", sign(jwt.SigningMethodEdDSA, edKey, "ed", func(c *Claims) { c.Audience = jwt.ClaimStrings{"other-app"} }), false},
+		{"one of several audiences", sign(jwt.SigningMethodEdDSA, edKey, "ed", func(c *Claims) { c.Audience = jwt.ClaimStrings{"other-app", cfg.Audience} }), true},
+		{"expired", sign(jwt.SigningMethodEdDSA, edKey, "ed", func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) }), false},
+		{"no expiry", sign(jwt.SigningMethodEdDSA, edKey, "ed", func(c *Claims) { c.ExpiresAt = nil }), false},
+		{"not yet valid", sign(jwt.SigningMethodEdDSA, edKey, "ed", func(c *Claims) { c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour)) }), false},
+		{"garbage", "not.a.token", false},
+	}
+
+	for _, tt := range tests {
+		_, err := ValidateJWT(tt.token, cfg)
+		if tt.valid != (err == nil) {
+			t.Errorf("%s: ValidateJWT = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestJWTIssuedAtKeepsSubseconds(t *testing.T) {
+	issuedAt := time.Date(2025, 1, 15, 10, 30, 0, 123456789, time.UTC)
+	encoded, err := jwt.NewNumericDate(issuedAt).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded jwt.NumericDate
+	if err := decoded.UnmarshalJSON(encoded); err != nil {
+		t.Fatal(err)
+	}
+	if want := issuedAt.Truncate(time.Microsecond); !decoded.Time.Equal(want) {
+		t.Errorf("iat round-trips as %v, want %v", decoded.Time, want)
+	}
+}
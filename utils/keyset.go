@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet holds the key used to sign access tokens and every public key that
+// is still accepted for verification. Rotating keys means adding a new key,
+// switching the signing key ID to it and removing the old key once the last
+// token signed with it has expired.
+type KeySet struct {
+	signingKID string
+	signingKey crypto.Signer
+	publicKeys map[string]crypto.PublicKey
+}
+
+// LoadKeySet reads every *.pem file in dir, using the file name without its
+// extension as the key ID. Private keys (PKCS#8 or PKCS#1) can sign, public
+// keys (PKIX) are only used to verify tokens signed before a rotation.
+// signingKID may be empty when the directory holds exactly one private key.
+func LoadKeySet(dir, signingKID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	keySet := &KeySet{publicKeys: make(map[string]crypto.PublicKey)}
+	privateKeys := make(map[string]crypto.Signer)
+
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey, publicKey, err := parsePEMKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if _, exists := keySet.publicKeys[kid]; exists {
+			return nil, fmt.Errorf("duplicate key ID %q", kid)
+		}
+		keySet.publicKeys[kid] = publicKey
+		if privateKey != nil {
+			privateKeys[kid] = privateKey
+		}
+	}
+
+	if signingKID == "" {
+		if len(privateKeys) != 1 {
+			return nil, fmt.Errorf("expected exactly one private key in %s, found %d; set the signing key ID", dir, len(privateKeys))
+		}
+		for kid := range privateKeys {
+			signingKID = kid
+		}
+	}
+
+	signingKey, ok := privateKeys[signingKID]
+	if !ok {
+		return nil, fmt.Errorf("no private key found for signing key ID %q", signingKID)
+	}
+
+	keySet.signingKID = signingKID
+	keySet.signingKey = signingKey
+	return keySet, nil
+}
+
+// NewEphemeralKeySet generates an in-memory Ed25519 key. Tokens signed with it
+// stop validating when the process restarts, so it is only meant for development.
+func NewEphemeralKeySet() (*KeySet, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := GenerateRandomToken(8)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeySet{
+		signingKID: kid,
+		signingKey: privateKey,
+		publicKeys: map[string]crypto.PublicKey{kid: publicKey},
+	}, nil
+}
+
+// SigningKeyID returns the key ID placed in the kid header of new tokens
+func (k *KeySet) SigningKeyID() string {
+	return k.signingKID
+}
+
+// signingMethod picks the JWS algorithm matching the signing key type
+func (k *KeySet) signingMethod() jwt.SigningMethod {
+	if _, ok := k.signingKey.(ed25519.PrivateKey); ok {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// verificationKey returns the public key for kid
+func (k *KeySet) verificationKey(kid string) (crypto.PublicKey, error) {
+	key, ok := k.publicKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	return key, nil
+}
+
+// JWKS returns the public keys in JSON Web Key Set format
+func (k *KeySet) JWKS() JWKSet {
+	kids := make([]string, 0, len(k.publicKeys))
+	for kid := range k.publicKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keySet := JWKSet{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		jwk, err := NewJWK(kid, k.publicKeys[kid])
+		if err != nil {
+			continue
+		}
+		keySet.Keys = append(keySet.Keys, jwk)
+	}
+	return keySet
+}
+
+func parsePEMKey(data []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY", "RSA PRIVATE KEY":
+		var parsed interface{}
+		var err error
+		if block.Type == "RSA PRIVATE KEY" {
+			parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		} else {
+			parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch key := parsed.(type) {
+		case *rsa.PrivateKey:
+			if key.N.BitLen() < 2048 {
+				return nil, nil, fmt.Errorf("RSA keys must be at least 2048 bits")
+			}
+			return key, key.Public(), nil
+		case ed25519.PrivateKey:
+			return key, key.Public(), nil
+		}
+		return nil, nil, fmt.Errorf("unsupported private key type %T", parsed)
+
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch key := parsed.(type) {
+		case *rsa.PublicKey:
+			if key.N.BitLen() < 2048 {
+				return nil, nil, fmt.Errorf("RSA keys must be at least 2048 bits")
+			}
+			return nil, key, nil
+		case ed25519.PublicKey:
+			return nil, key, nil
+		}
+		return nil, nil, fmt.Errorf("unsupported public key type %T", parsed)
+	}
+
+	return nil, nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newRSAKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func privateKeyPEM(t *testing.T, key crypto.Signer) []byte {
+	t.Helper()
+
+	if rsaKey, ok := key.(*rsa.PrivateKey); ok {
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicKeyPEM(t *testing.T, key crypto.PublicKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// keyDir writes the given files into a fresh directory
+func keyDir(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadKeySet(t *testing.T) {
+	current := newEd25519Key(t)
+	previous := newRSAKey(t, 2048)
+
+	dir := keyDir(t, map[string][]byte{
+		"2025-06.pem": privateKeyPEM(t, current),
+		"2025-01.pem": publicKeyPEM(t, previous.Public()),
+		"README.txt":  []byte("not a key"),
+	})
+
+	keySet, err := LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	if keySet.SigningKeyID() != "2025-06" || keySet.signingMethod().Alg() != "EdDSA" {
+		t.Errorf("signing with %q (%s), want 2025-06 (EdDSA)", keySet.SigningKeyID(), keySet.signingMethod().Alg())
+	}
+	for _, kid := range []string{"2025-06", "2025-01"} {
+		if _, err := keySet.verificationKey(kid); err != nil {
+			t.Errorf("verificationKey(%s): %v", kid, err)
+		}
+	}
+	if _, err := keySet.verificationKey("README"); err == nil {
+		t.Error("a non-PEM file was loaded as a key")
+	}
+	if got := len(keySet.JWKS().Keys); got != 2 {
+		t.Errorf("JWKS has %d keys, want 2", got)
+	}
+}
+
+func TestLoadKeySetSigningKeyChoice(t *testing.T) {
+	dir := keyDir(t, map[string][]byte{
+		"old.pem": privateKeyPEM(t, newRSAKey(t, 2048)),
+		"new.pem": privateKeyPEM(t, newEd25519Key(t)),
+	})
+
+	if _, err := LoadKeySet(dir, ""); err == nil {
+		t.Error("two private keys without a signing key ID were accepted")
+	}
+	if _, err := LoadKeySet(dir, "missing"); err == nil {
+		t.Error("an unknown signing key ID was accepted")
+	}
+
+	keySet, err := LoadKeySet(dir, "old")
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	if keySet.SigningKeyID() != "old" || keySet.signingMethod().Alg() != "RS256" {
+		t.Errorf("signing with %q (%s), want old (RS256)", keySet.SigningKeyID(), keySet.signingMethod().Alg())
+	}
+}
+
+func TestLoadKeySetRejects(t *testing.T) {
+	signer := privateKeyPEM(t, newEd25519Key(t))
+
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		err   string
+	}{
+		{"empty directory", map[string][]byte{}, "expected exactly one private key"},
+		{"only public keys", map[string][]byte{"a.pem": publicKeyPEM(t, newEd25519Key(t).Public())}, "expected exactly one private key"},
+		{"short RSA key", map[string][]byte{"a.pem": signer, "b.pem": privateKeyPEM(t, newRSAKey(t, 1024))}, "at least 2048 bits"},
+		{"not PEM", map[string][]byte{"a.pem": signer, "b.pem": []byte("garbage")}, "no PEM block"},
+		{"unknown block", map[string][]byte{"a.pem": signer, "b.pem": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}})}, "unsupported PEM block"},
+	}
+
+	for _, tt := range tests {
+		_, err := LoadKeySet(keyDir(t, tt.files), "")
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: LoadKeySet = %v, want an error containing %q", tt.name, err, tt.err)
+		}
+	}
+}
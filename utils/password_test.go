@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cheap parameters keep the tests fast; the encoding is the same at any cost
+var testArgon2idParams = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestPasswordHasherRoundTrip(t *testing.T) {
+	hashers := map[string]PasswordHasher{
+		"argon2id": Argon2idHasher{Params: testArgon2idParams},
+		"bcrypt":   BcryptHasher{Cost: bcrypt.MinCost},
+	}
+
+	for name, hasher := range hashers {
+		first, err := hasher.Hash("correct horse battery staple")
+		if err != nil {
+			t.Fatalf("%s: Hash: %v", name, err)
+		}
+		second, err := hasher.Hash("correct horse battery staple")
+		if err != nil {
+			t.Fatalf("%s: Hash: %v", name, err)
+		}
+		if first == second {
+			t.Errorf("%s: two hashes of the same password are identical, the salt is not random", name)
+		}
+
+		if !hasher.Verify("correct horse battery staple", first) {
+			t.Errorf("%s: the right password did not verify", name)
+		}
+		if hasher.Verify("correct horse battery stapler", first) {
+			t.Errorf("%s: a wrong password verified", name)
+		}
+		if !CheckPassword("correct horse battery staple", first) || CheckPassword("", first) {
+			t.Errorf("%s: CheckPassword did not pick the hash's own algorithm", name)
+		}
+		if hasher.NeedsRehash(first) {
+			t.Errorf("%s: a fresh hash needs a rehash", name)
+		}
+	}
+}
+
+func TestArgon2idHashFormat(t *testing.T) {
+	hash, err := Argon2idHasher{Params: testArgon2idParams}.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("hash = %s, want the PHC format with its parameters", hash)
+	}
+
+	// A hash from another implementation (the RFC 9106 reference CLI)
+	reference := "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+	if !CheckPassword("password", reference) {
+		t.Error("a reference argon2id hash did not verify")
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	current := Argon2idHasher{Params: testArgon2idParams}
+	hash, err := current.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash, err := BcryptHasher{Cost: bcrypt.MinCost}.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := func(edit func(*Argon2idParams)) Argon2idHasher {
+		params := testArgon2idParams
+		edit(&params)
+		return Argon2idHasher{Params: params}
+	}
+
+	tests := []struct {
+		name   string
+		hasher PasswordHasher
+		hash   string
+		want   bool
+	}{
+		{"same parameters", current, hash, false},
+		{"more memory", changed(func(p *Argon2idParams) { p.Memory *= 2 }), hash, true},
+		{"more iterations", changed(func(p *Argon2idParams) { p.Iterations++ }), hash, true},
+		{"more parallelism", changed(func(p *Argon2idParams) { p.Parallelism++ }), hash, true},
+		{"longer salt", changed(func(p *Argon2idParams) { p.SaltLength = 32 }), hash, true},
+		{"longer key", changed(func(p *Argon2idParams) { p.KeyLength = 64 }), hash, true},
+		{"bcrypt to argon2id", current, bcryptHash, true},
+		{"argon2id to bcrypt", BcryptHasher{Cost: bcrypt.MinCost}, hash, true},
+		{"same bcrypt cost", BcryptHasher{Cost: bcrypt.MinCost}, bcryptHash, false},
+		{"higher bcrypt cost", BcryptHasher{Cost: bcrypt.MinCost + 1}, bcryptHash, true},
+		{"unreadable hash", current, "garbage", true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.hasher.NeedsRehash(tt.hash); got != tt.want {
+			t.Errorf("%s: NeedsRehash = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Hashes made with older settings still verify after the change
+	if !CheckPassword("secret", hash) || !CheckPassword("secret", bcryptHash) {
+		t.Error("an older hash no longer verifies")
+	}
+}
+
+func TestMalformedPasswordHashes(t *testing.T) {
+	valid, err := Argon2idHasher{Params: testArgon2idParams}.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, "$")
+	with := func(index int, value string) string {
+		changed := append([]string{}, parts...)
+		changed[index] = value
+		return strings.Join(changed, "$")
+	}
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{"empty", ""},
+		{"plain text", "secret"},
+		{"argon2i", with(1, "argon2i")},
+		{"missing field", strings.Join(parts[:5], "$")},
+		{"extra field", valid + "$extra"},
+		{"other version", with(2, "v=16")},
+		{"no version", with(2, "19")},
+		{"no parameters", with(3, "")},
+		{"zero memory", with(3, "m=0,t=1,p=1")},
+		{"zero iterations", with(3, "m=64,t=0,p=1")},
+		{"zero parallelism", with(3, "m=64,t=1,p=0")},
+		{"parallelism overflow", with(3, "m=64,t=1,p=256")},
+		{"negative memory", with(3, "m=-64,t=1,p=1")},
+		{"salt not base64", with(4, "!!!")},
+		{"key not base64", with(5, "!!!")},
+		{"empty key", with(5, "")},
+		{"truncated key", with(5, parts[5][:10])},
+		{"truncated bcrypt", "$2a$04$abc"},
+		{"unknown scheme", "$5$rounds=5000$salt$hash"},
+	}
+
+	for _, tt := range tests {
+		if CheckPassword("secret", tt.hash) {
+			t.Errorf("%s: %q verified", tt.name, tt.hash)
+		}
+		if !(Argon2idHasher{Params: testArgon2idParams}).NeedsRehash(tt.hash) {
+			t.Errorf("%s: %q does not need a rehash", tt.name, tt.hash)
+		}
+	}
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	if hasher, err := NewPasswordHasher(PasswordAlgorithmArgon2id, testArgon2idParams, 0); err != nil || hasher != (Argon2idHasher{Params: testArgon2idParams}) {
+		t.Errorf("argon2id: NewPasswordHasher = %v, %v", hasher, err)
+	}
+	if hasher, err := NewPasswordHasher(PasswordAlgorithmBcrypt, testArgon2idParams, 12); err != nil || hasher != (BcryptHasher{Cost: 12}) {
+		t.Errorf("bcrypt: NewPasswordHasher = %v, %v", hasher, err)
+	}
+
+	for _, cost := range []int{bcrypt.MinCost - 1, bcrypt.MaxCost + 1} {
+		if _, err := NewPasswordHasher(PasswordAlgorithmBcrypt, testArgon2idParams, cost); err == nil {
+			t.Errorf("bcrypt cost %d was accepted", cost)
+		}
+	}
+	if _, err := NewPasswordHasher("md5", testArgon2idParams, 12); err == nil {
+		t.Error("an unknown algorithm was accepted")
+	}
+}
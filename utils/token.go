@@ -2,28 +2,41 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-type ResetTokenClaims struct {
+const (
+	TokenTypeVerifyEmail   = "verify_email"
+	TokenTypeMFAPending    = "mfa_pending"
+	TokenTypeUnlockAccount = "unlock_account"
+)
+
+// PurposeTokenClaims are the claims of a short-lived token that is only
+// accepted for the single purpose named in Type
+type PurposeTokenClaims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
-	Type   string `json:"type"` // "reset_password"
+	Type   string `json:"type"`
 	jwt.RegisteredClaims
 }
 
-// GenerateResetPasswordToken generates a JWT token for password reset
-func GenerateResetPasswordToken(userID, email, secretKey string) (string, error) {
-	claims := ResetTokenClaims{
+type EmailVerificationClaims = PurposeTokenClaims
+
+// GeneratePurposeToken generates a JWT token that is only valid for tokenType
+func GeneratePurposeToken(userID, email, tokenType, secretKey string, ttl time.Duration) (string, error) {
+	claims := PurposeTokenClaims{
 		UserID: userID,
 		Email:  email,
-		Type:   "reset_password",
+		Type:   tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)), // 1 hour expiry
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
@@ -33,9 +46,9 @@ func GenerateResetPasswordToken(userID, email, secretKey string) (string, error)
 	return token.SignedString([]byte(secretKey))
 }
 
-// ValidateResetPasswordToken validates and parses a reset password token
-func ValidateResetPasswordToken(tokenString, secretKey string) (*ResetTokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &ResetTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+// ValidatePurposeToken validates and parses a token issued for tokenType
+func ValidatePurposeToken(tokenString, tokenType, secretKey string) (*PurposeTokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &PurposeTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
@@ -46,9 +59,9 @@ func ValidateResetPasswordToken(tokenString, secretKey string) (*ResetTokenClaim
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*ResetTokenClaims); ok && token.Valid {
-		// Check if token type is correct
-		if claims.Type != "reset_password" {
+	if claims, ok := token.Claims.(*PurposeTokenClaims); ok && token.Valid {
+		// A token issued for one purpose must never be accepted for another
+		if claims.Type != tokenType {
 			return nil, fmt.Errorf("invalid token type")
 		}
 		return claims, nil
@@ -57,6 +70,53 @@ func ValidateResetPasswordToken(tokenString, secretKey string) (*ResetTokenClaim
 	return nil, fmt.Errorf("invalid token")
 }
 
+// GenerateEmailVerificationToken generates a JWT token for email verification
+func GenerateEmailVerificationToken(userID, email, secretKey string) (string, error) {
+	return GeneratePurposeToken(userID, email, TokenTypeVerifyEmail, secretKey, 24*time.Hour) // 24 hours expiry
+}
+
+// ValidateEmailVerificationToken validates and parses an email verification token
+func ValidateEmailVerificationToken(tokenString, secretKey string) (*EmailVerificationClaims, error) {
+	return ValidatePurposeToken(tokenString, TokenTypeVerifyEmail, secretKey)
+}
+
+// recoveryCodeBytes gives each recovery code 80 bits of entropy, too many to
+// brute-force from the SHA-256 hashes they are stored as
+const recoveryCodeBytes = 10
+
+// GenerateRecoveryCodes generates one-time MFA recovery codes formatted as
+// "xxxxx-xxxxx-xxxxx-xxxxx"
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		token, err := GenerateRandomToken(recoveryCodeBytes)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, NormalizeRecoveryCode(token))
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode brings a recovery code as typed by a user into the
+// form it was hashed in: lowercase, in dash-separated groups of five. Codes
+// issued before they were lengthened ("xxxxx-xxxxx") keep working.
+func NormalizeRecoveryCode(code string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range strings.ToLower(code) {
+		if r == '-' || unicode.IsSpace(r) {
+			continue
+		}
+		if n > 0 && n%5 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteRune(r)
+		n++
+	}
+	return b.String()
+}
+
 // GenerateRandomToken generates a random token for additional security
 func GenerateRandomToken(length int) (string, error) {
 	bytes := make([]byte, length)
@@ -66,3 +126,9 @@ func GenerateRandomToken(length int) (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// HashToken returns the SHA-256 hex digest of an opaque token so it can be
+// stored and looked up without keeping the raw value
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
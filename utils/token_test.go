@@ -0,0 +1,42 @@
+package utils
+
+import "testing"
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"3f9a1-0c7b2-9d4e8-a61f0", "3f9a1-0c7b2-9d4e8-a61f0"},
+		{"3F9A10C7B29D4E8A61F0", "3f9a1-0c7b2-9d4e8-a61f0"},
+		{" 3f9a1 0c7b2-9d4e8  a61f0 ", "3f9a1-0c7b2-9d4e8-a61f0"},
+		// Codes issued before they were lengthened
+		{"abcde-12345", "abcde-12345"},
+		{"ABCDE12345", "abcde-12345"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeRecoveryCode(tt.input); got != tt.want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		// 80 bits as 20 hex digits in four groups
+		if len(code) != 23 || NormalizeRecoveryCode(code) != code {
+			t.Errorf("malformed recovery code %q", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate recovery code %q", code)
+		}
+		seen[code] = true
+	}
+}